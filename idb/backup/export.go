@@ -0,0 +1,226 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package backup exports and imports whole IndexedDB databases as newline-delimited JSON.
+//
+// The first line describes the database, followed by one line for each object store's schema, then one line for each record:
+//
+//	{"database":{"name":"mydb","version":3}}
+//	{"store":{"name":"users","keyPath":"id","autoIncrement":false,"indexes":[{"name":"byEmail","keyPath":"email","unique":true,"multiEntry":false}]}}
+//	{"record":{"store":"users","key":{"string":"alice"},"value":{"id":"alice","email":"alice@example.com"}}}
+//
+// Keys use idbkey.Key's JSON encoding. Values are JSON, except for values JSON can't represent, like Dates and typed arrays, which are encoded as an object with a single "$"-prefixed field naming their type, like {"$date":0}. Blob and File values are not supported.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+type line struct {
+	Database *databaseSchema `json:"database,omitempty"`
+	Store    *storeSchema    `json:"store,omitempty"`
+	Record   *record         `json:"record,omitempty"`
+}
+
+type databaseSchema struct {
+	Name    string `json:"name"`
+	Version uint   `json:"version"`
+}
+
+type storeSchema struct {
+	Name          string        `json:"name"`
+	KeyPath       interface{}   `json:"keyPath"`
+	AutoIncrement bool          `json:"autoIncrement"`
+	Indexes       []indexSchema `json:"indexes"`
+}
+
+type indexSchema struct {
+	Name       string      `json:"name"`
+	KeyPath    interface{} `json:"keyPath"`
+	Unique     bool        `json:"unique"`
+	MultiEntry bool        `json:"multiEntry"`
+}
+
+type record struct {
+	Store string          `json:"store"`
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Export writes db's schema and every record to w as newline-delimited JSON.
+// Records are read with cursors in a single read-only transaction and written as they're read, so w must not wait on other JavaScript events, like a pending fetch.
+func Export(ctx context.Context, db *idb.Database, w io.Writer) error {
+	name, err := db.Name()
+	if err != nil {
+		return err
+	}
+	version, err := db.Version()
+	if err != nil {
+		return err
+	}
+	if err := writeLine(w, line{Database: &databaseSchema{Name: name, Version: version}}); err != nil {
+		return err
+	}
+	storeNames, err := db.ObjectStoreNames()
+	if err != nil || len(storeNames) == 0 {
+		return err
+	}
+
+	return txnutil.Run(ctx, db, idb.TransactionReadOnly, storeNames, func(txn *idb.Transaction) error {
+		stores := make([]*idb.ObjectStore, 0, len(storeNames))
+		for _, storeName := range storeNames {
+			store, err := txn.ObjectStore(storeName)
+			if err != nil {
+				return err
+			}
+			schema, err := exportStoreSchema(store, storeName)
+			if err != nil {
+				return err
+			}
+			if err := writeLine(w, line{Store: schema}); err != nil {
+				return err
+			}
+			stores = append(stores, store)
+		}
+		for i, store := range stores {
+			if err := exportRecords(ctx, store, storeNames[i], w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func exportStoreSchema(store *idb.ObjectStore, name string) (*storeSchema, error) {
+	jsKeyPath, err := store.KeyPath()
+	if err != nil {
+		return nil, err
+	}
+	keyPath, err := keyPathFromJS(jsKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	autoIncrement, err := store.AutoIncrement()
+	if err != nil {
+		return nil, err
+	}
+	indexNames, err := store.IndexNames()
+	if err != nil {
+		return nil, err
+	}
+	schema := &storeSchema{
+		Name:          name,
+		KeyPath:       keyPath,
+		AutoIncrement: autoIncrement,
+		Indexes:       make([]indexSchema, 0, len(indexNames)),
+	}
+	for _, indexName := range indexNames {
+		index, err := store.Index(indexName)
+		if err != nil {
+			return nil, err
+		}
+		jsKeyPath, err := index.KeyPath()
+		if err != nil {
+			return nil, err
+		}
+		keyPath, err := keyPathFromJS(jsKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		unique, err := index.Unique()
+		if err != nil {
+			return nil, err
+		}
+		multiEntry, err := index.MultiEntry()
+		if err != nil {
+			return nil, err
+		}
+		schema.Indexes = append(schema.Indexes, indexSchema{
+			Name:       indexName,
+			KeyPath:    keyPath,
+			Unique:     unique,
+			MultiEntry: multiEntry,
+		})
+	}
+	return schema, nil
+}
+
+func exportRecords(ctx context.Context, store *idb.ObjectStore, storeName string, w io.Writer) error {
+	cursorRequest, err := store.OpenCursor(idb.CursorNext)
+	if err != nil {
+		return err
+	}
+	var value bytes.Buffer
+	return cursorRequest.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		jsKey, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		key, err := idb.ParseKey(jsKey)
+		if err != nil {
+			return err
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		jsValue, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		value.Reset()
+		if err := encodeValue(&value, safejs.Safe(jsValue)); err != nil {
+			return err
+		}
+		return writeLine(w, line{Record: &record{
+			Store: storeName,
+			Key:   encodedKey,
+			Value: value.Bytes(),
+		}})
+	})
+}
+
+func writeLine(w io.Writer, l line) error {
+	encoded, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(encoded, '\n'))
+	return err
+}
+
+// keyPathFromJS converts a key path to a JSON-compatible value: nil, a string, or a slice of strings
+func keyPathFromJS(keyPath js.Value) (interface{}, error) {
+	value := safejs.Safe(keyPath)
+	if value.IsNull() || value.IsUndefined() {
+		return nil, nil
+	}
+	if value.Type() == safejs.TypeString {
+		return value.String()
+	}
+	length, err := value.Length()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, length)
+	for i := 0; i < length; i++ {
+		path, err := value.Index(i)
+		if err != nil {
+			return nil, err
+		}
+		pathStr, err := path.String()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, pathStr)
+	}
+	return paths, nil
+}
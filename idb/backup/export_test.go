@@ -0,0 +1,104 @@
+//go:build js && wasm
+// +build js,wasm
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testDB(tb testing.TB) *idb.Database {
+	tb.Helper()
+	ctx := context.Background()
+	req, err := idb.Global().Open(ctx, idbtest.DBName(tb), 2, func(db *idb.Database, oldVersion, newVersion uint) error {
+		users, err := db.CreateObjectStore("users", idb.ObjectStoreOptions{
+			KeyPath: js.ValueOf("id"),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = users.CreateIndex("byEmail", js.ValueOf("email"), idb.IndexOptions{Unique: true})
+		if err != nil {
+			return err
+		}
+		_, err = users.CreateIndex("byTag", js.ValueOf("tags"), idb.IndexOptions{MultiEntry: true})
+		if err != nil {
+			return err
+		}
+		_, err = db.CreateObjectStore("events", idb.ObjectStoreOptions{AutoIncrement: true})
+		return err
+	})
+	assert.NoError(tb, err)
+	db, err := req.Await(ctx)
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, db.Close())
+	})
+
+	txn, err := db.Transaction(idb.TransactionReadWrite, "users", "events")
+	assert.NoError(tb, err)
+	users, err := txn.ObjectStore("users")
+	assert.NoError(tb, err)
+	_, err = users.Put(evalJS(`({id: "alice", email: "alice@example.com", tags: ["a", "b"], joined: new Date(1000)})`))
+	assert.NoError(tb, err)
+	events, err := txn.ObjectStore("events")
+	assert.NoError(tb, err)
+	for _, key := range []string{`1`, `new Date(2000)`, `new Uint8Array([1, 2])`, `["x", 1]`} {
+		_, err = events.PutKey(evalJS(key), evalJS(`({data: new Uint8Array([3])})`))
+		assert.NoError(tb, err)
+	}
+	assert.NoError(tb, txn.Await(ctx))
+	return db
+}
+
+func exportString(tb testing.TB, db *idb.Database) string {
+	tb.Helper()
+	var buf bytes.Buffer
+	assert.NoError(tb, Export(context.Background(), db, &buf))
+	return buf.String()
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	db := testDB(t)
+	name, err := db.Name()
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(exportString(t, db), "\n"), "\n")
+	assert.Equal(t, []string{
+		fmt.Sprintf(`{"database":{"name":%q,"version":2}}`, name),
+		`{"store":{"name":"events","keyPath":null,"autoIncrement":true,"indexes":[]}}`,
+		`{"store":{"name":"users","keyPath":"id","autoIncrement":false,"indexes":[{"name":"byEmail","keyPath":"email","unique":true,"multiEntry":false},{"name":"byTag","keyPath":"tags","unique":false,"multiEntry":true}]}}`,
+		`{"record":{"store":"events","key":{"number":1},"value":{"data":{"$view":"Uint8Array","data":"Aw=="}}}}`,
+		`{"record":{"store":"events","key":{"date":2000},"value":{"data":{"$view":"Uint8Array","data":"Aw=="}}}}`,
+		`{"record":{"store":"events","key":{"binary":"AQI="},"value":{"data":{"$view":"Uint8Array","data":"Aw=="}}}}`,
+		`{"record":{"store":"events","key":{"array":[{"string":"x"},{"number":1}]},"value":{"data":{"$view":"Uint8Array","data":"Aw=="}}}}`,
+		`{"record":{"store":"users","key":{"string":"alice"},"value":{"id":"alice","email":"alice@example.com","tags":["a","b"],"joined":{"$date":1000}}}}`,
+	}, lines)
+}
+
+func TestExportUnsupportedValue(t *testing.T) {
+	t.Parallel()
+	db := testDB(t)
+	txn, err := db.Transaction(idb.TransactionReadWrite, "events")
+	assert.NoError(t, err)
+	events, err := txn.ObjectStore("events")
+	assert.NoError(t, err)
+	_, err = events.Add(evalJS(`new Blob(["a"])`))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(context.Background()))
+
+	err = Export(context.Background(), db, new(bytes.Buffer))
+	assert.ErrorIs(t, err, errUnsupportedValue)
+}
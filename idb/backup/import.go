@@ -0,0 +1,265 @@
+//go:build js && wasm
+// +build js,wasm
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+// DefaultBatchSize is the default number of records put in each import transaction
+const DefaultBatchSize = 1000
+
+// ErrExists is returned by Import when the database to import already exists
+var ErrExists = errors.New("backup: database already exists")
+
+// ImportOptions contains all available options for importing a database
+type ImportOptions struct {
+	// Name is the imported database's name. Defaults to the exported database's name.
+	Name string
+	// BatchSize is the number of records put in each transaction. Defaults to DefaultBatchSize.
+	BatchSize int
+}
+
+// Import creates a new database from a dump written by Export, then returns it open.
+// The schema is created in an upgrade to the exported version, then records are put in batches as they're read from r. Each batch is read inside its transaction, so r must not wait on other JavaScript events. If the import fails, the partially imported database is deleted.
+//
+// Returns ErrExists if the database already exists, which is left unchanged.
+func Import(ctx context.Context, factory *idb.Factory, r io.Reader, options ImportOptions) (*idb.Database, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	reader := &lineReader{reader: bufio.NewReader(r)}
+	first, err := reader.next()
+	if err == io.EOF {
+		return nil, errors.New("backup: missing database line")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if first.Database == nil {
+		return nil, fmt.Errorf("backup: line %d: expected database", reader.lineNumber)
+	}
+	if options.Name == "" {
+		options.Name = first.Database.Name
+	}
+
+	var stores []storeSchema
+	var pending *line
+	for {
+		l, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if l.Store == nil {
+			pending = &l
+			break
+		}
+		stores = append(stores, *l.Store)
+	}
+
+	db, err := createDatabase(ctx, factory, options.Name, first.Database.Version, stores)
+	if err != nil {
+		return nil, err
+	}
+	err = importRecords(ctx, db, stores, reader, pending, options.BatchSize)
+	if err != nil {
+		_ = db.Close()
+		if req, deleteErr := factory.DeleteDatabase(options.Name); deleteErr == nil {
+			_ = req.Await(ctx)
+		}
+		return nil, err
+	}
+	return db, nil
+}
+
+// createDatabase opens a new database with the given schema, or returns ErrExists if it already exists
+func createDatabase(ctx context.Context, factory *idb.Factory, name string, version uint, stores []storeSchema) (*idb.Database, error) {
+	if version == 0 {
+		version = 1
+	}
+	created, exists := false, false
+	req, err := factory.Open(ctx, name, version, func(db *idb.Database, oldVersion, newVersion uint) error {
+		if oldVersion != 0 {
+			// closing the connection aborts the upgrade, leaving the existing database unchanged
+			exists = true
+			return db.Close()
+		}
+		created = true
+		return createSchema(db, stores)
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err := req.Await(ctx)
+	if exists {
+		return nil, ErrExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		_ = db.Close()
+		return nil, ErrExists
+	}
+	return db, nil
+}
+
+func createSchema(db *idb.Database, stores []storeSchema) error {
+	for _, storeSchema := range stores {
+		keyPath, err := keyPathToJS(storeSchema.KeyPath)
+		if err != nil {
+			return err
+		}
+		store, err := db.CreateObjectStore(storeSchema.Name, idb.ObjectStoreOptions{
+			KeyPath:       keyPath,
+			AutoIncrement: storeSchema.AutoIncrement,
+		})
+		if err != nil {
+			return err
+		}
+		for _, indexSchema := range storeSchema.Indexes {
+			keyPath, err := keyPathToJS(indexSchema.KeyPath)
+			if err != nil {
+				return err
+			}
+			_, err = store.CreateIndex(indexSchema.Name, keyPath, idb.IndexOptions{
+				Unique:     indexSchema.Unique,
+				MultiEntry: indexSchema.MultiEntry,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importRecords puts every remaining record from reader, starting with pending if set
+func importRecords(ctx context.Context, db *idb.Database, stores []storeSchema, reader *lineReader, pending *line, batchSize int) error {
+	if pending == nil {
+		return nil
+	}
+	if len(stores) == 0 {
+		return fmt.Errorf("backup: line %d: record found without any stores", reader.lineNumber)
+	}
+	inlineKeys := make(map[string]bool, len(stores))
+	storeNames := make([]string, 0, len(stores))
+	for _, store := range stores {
+		inlineKeys[store.Name] = store.KeyPath != nil
+		storeNames = append(storeNames, store.Name)
+	}
+
+	for pending != nil {
+		err := txnutil.Run(ctx, db, idb.TransactionReadWrite, storeNames, func(txn *idb.Transaction) error {
+			for count := 0; pending != nil && count < batchSize; count++ {
+				err := putRecord(txn, inlineKeys, pending, reader.lineNumber)
+				if err == nil {
+					pending, err = reader.nextRecord()
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putRecord(txn *idb.Transaction, inlineKeys map[string]bool, l *line, lineNumber int) error {
+	if l.Record == nil {
+		return fmt.Errorf("backup: line %d: expected record", lineNumber)
+	}
+	inlineKey, ok := inlineKeys[l.Record.Store]
+	if !ok {
+		return fmt.Errorf("backup: line %d: unknown store %q", lineNumber, l.Record.Store)
+	}
+	store, err := txn.ObjectStore(l.Record.Store)
+	if err != nil {
+		return err
+	}
+	value, err := decodeValue(l.Record.Value)
+	if err != nil {
+		return fmt.Errorf("backup: line %d: %w", lineNumber, err)
+	}
+	jsValue := safejs.Unsafe(value)
+	if inlineKey {
+		_, err = store.Put(jsValue)
+		return err
+	}
+	var key idbkey.Key
+	if err := json.Unmarshal(l.Record.Key, &key); err != nil {
+		return fmt.Errorf("backup: line %d: %w", lineNumber, err)
+	}
+	jsKey, err := idb.KeyValue(key)
+	if err != nil {
+		return err
+	}
+	_, err = store.PutKey(jsKey, jsValue)
+	return err
+}
+
+// lineReader reads lines written by Export, skipping blank lines
+type lineReader struct {
+	reader     *bufio.Reader
+	lineNumber int
+}
+
+func (r *lineReader) next() (line, error) {
+	for {
+		b, err := r.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(b) == 0) {
+			return line{}, err
+		}
+		r.lineNumber++
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			continue
+		}
+		var l line
+		if err := json.Unmarshal(b, &l); err != nil {
+			return line{}, fmt.Errorf("backup: line %d: %w", r.lineNumber, err)
+		}
+		return l, nil
+	}
+}
+
+// nextRecord returns the next line, or nil at the end of the input
+func (r *lineReader) nextRecord() (*line, error) {
+	l, err := r.next()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// keyPathToJS converts a key path decoded from JSON into a JS value
+func keyPathToJS(keyPath interface{}) (js.Value, error) {
+	if keyPath == nil {
+		return js.Null(), nil
+	}
+	value, err := safejs.ValueOf(keyPath)
+	return safejs.Unsafe(value), err
+}
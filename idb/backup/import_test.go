@@ -0,0 +1,77 @@
+//go:build js && wasm
+// +build js,wasm
+
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func TestImportRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	exported := exportString(t, db)
+
+	name := idbtest.DBName(t)
+	imported, err := Import(ctx, idb.Global(), strings.NewReader(exported), ImportOptions{
+		Name:      name,
+		BatchSize: 2,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer func() {
+		assert.NoError(t, imported.Close())
+	}()
+	version, err := imported.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+
+	// only the database line differs, since it contains the name
+	exportedLines := strings.SplitN(exported, "\n", 2)
+	importedLines := strings.SplitN(exportString(t, imported), "\n", 2)
+	assert.Equal(t, exportedLines[1], importedLines[1])
+}
+
+func TestImportExists(t *testing.T) {
+	t.Parallel()
+	db := testDB(t)
+	exported := exportString(t, db)
+
+	_, err := Import(context.Background(), idb.Global(), strings.NewReader(exported), ImportOptions{})
+	assert.ErrorIs(t, err, ErrExists)
+}
+
+func TestImportInvalid(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "missing database", input: `{"store":{"name":"a","keyPath":null,"autoIncrement":false,"indexes":[]}}`},
+		{name: "invalid JSON", input: `{"database":`},
+		{name: "unknown store", input: `{"database":{"name":"","version":1}}
+{"store":{"name":"a","keyPath":null,"autoIncrement":false,"indexes":[]}}
+{"record":{"store":"b","key":{"number":1},"value":1}}`},
+		{name: "invalid value", input: `{"database":{"name":"","version":1}}
+{"store":{"name":"a","keyPath":null,"autoIncrement":false,"indexes":[]}}
+{"record":{"store":"a","key":{"number":1},"value":{"$unknown":1}}}`},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Import(context.Background(), idb.Global(), strings.NewReader(tc.input), ImportOptions{
+				Name: idbtest.DBName(t),
+			})
+			assert.Error(t, err)
+		})
+	}
+}
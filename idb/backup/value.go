@@ -0,0 +1,568 @@
+//go:build js && wasm
+// +build js,wasm
+
+package backup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsArray       safejs.Value
+	jsArrayBuffer safejs.Value
+	jsBlob        safejs.Value
+	jsDate        safejs.Value
+	jsMap         safejs.Value
+	jsObject      safejs.Value
+	jsSet         safejs.Value
+	jsUint8Array  safejs.Value
+
+	errUnsupportedValue = errors.New("backup: unsupported value")
+
+	// viewTypes are the ArrayBuffer view constructors encoded as {"$view":name}. Imports only construct these, since the name comes from the backup file.
+	viewTypes = map[string]bool{
+		"BigInt64Array":     true,
+		"BigUint64Array":    true,
+		"DataView":          true,
+		"Float32Array":      true,
+		"Float64Array":      true,
+		"Int8Array":         true,
+		"Int16Array":        true,
+		"Int32Array":        true,
+		"Uint8Array":        true,
+		"Uint8ClampedArray": true,
+		"Uint16Array":       true,
+		"Uint32Array":       true,
+	}
+)
+
+func init() {
+	for _, global := range []struct {
+		name  string
+		value *safejs.Value
+	}{
+		{"Array", &jsArray},
+		{"ArrayBuffer", &jsArrayBuffer},
+		{"Blob", &jsBlob},
+		{"Date", &jsDate},
+		{"Map", &jsMap},
+		{"Object", &jsObject},
+		{"Set", &jsSet},
+		{"Uint8Array", &jsUint8Array},
+	} {
+		value, err := safejs.Global().Get(global.name)
+		if err != nil {
+			panic(err)
+		}
+		*global.value = value
+	}
+}
+
+// encodeValue appends the typed JSON encoding of value to buf.
+// JSON values are encoded as-is. Other values are encoded as an object with a single "$"-prefixed field naming their type, like {"$date":0}. Objects with "$"-prefixed fields of their own are wrapped in {"$object":...}.
+func encodeValue(buf *bytes.Buffer, value safejs.Value) error {
+	switch value.Type() {
+	case safejs.TypeUndefined:
+		buf.WriteString(`{"$undefined":true}`)
+	case safejs.TypeNull:
+		buf.WriteString("null")
+	case safejs.TypeBoolean:
+		b, err := value.Bool()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(b))
+	case safejs.TypeNumber:
+		number, err := value.Float()
+		if err != nil {
+			return err
+		}
+		encodeNumber(buf, number)
+	case safejs.TypeString:
+		str, err := value.String()
+		if err != nil {
+			return err
+		}
+		return encodeString(buf, str)
+	case safejs.TypeObject:
+		return encodeObject(buf, value)
+	default:
+		return fmt.Errorf("%w: %s", errUnsupportedValue, value.Type())
+	}
+	return nil
+}
+
+func encodeNumber(buf *bytes.Buffer, number float64) {
+	switch {
+	case math.IsNaN(number):
+		buf.WriteString(`{"$number":"NaN"}`)
+	case math.IsInf(number, 1):
+		buf.WriteString(`{"$number":"Infinity"}`)
+	case math.IsInf(number, -1):
+		buf.WriteString(`{"$number":"-Infinity"}`)
+	default:
+		buf.WriteString(strconv.FormatFloat(number, 'g', -1, 64))
+	}
+}
+
+func encodeString(buf *bytes.Buffer, str string) error {
+	encoded, err := json.Marshal(str)
+	buf.Write(encoded)
+	return err
+}
+
+func encodeObject(buf *bytes.Buffer, value safejs.Value) error {
+	for _, tc := range []struct {
+		constructor safejs.Value
+		encode      func(*bytes.Buffer, safejs.Value) error
+	}{
+		{jsDate, encodeDate},
+		{jsArrayBuffer, encodeArrayBuffer},
+		{jsMap, encodeMap},
+		{jsSet, encodeSet},
+		{jsBlob, encodeBlob},
+	} {
+		isInstance, err := value.InstanceOf(tc.constructor)
+		if err != nil {
+			return err
+		}
+		if isInstance {
+			return tc.encode(buf, value)
+		}
+	}
+	isView, err := jsArrayBuffer.Call("isView", value)
+	if err != nil {
+		return err
+	}
+	if truthy, err := isView.Truthy(); err != nil || truthy {
+		if err != nil {
+			return err
+		}
+		return encodeView(buf, value)
+	}
+	isArray, err := jsArray.Call("isArray", value)
+	if err != nil {
+		return err
+	}
+	if truthy, err := isArray.Truthy(); err != nil || truthy {
+		if err != nil {
+			return err
+		}
+		return encodeArray(buf, value)
+	}
+	return encodePlainObject(buf, value)
+}
+
+func encodeDate(buf *bytes.Buffer, value safejs.Value) error {
+	millis, err := value.Call("getTime")
+	if err != nil {
+		return err
+	}
+	number, err := millis.Float()
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`{"$date":`)
+	encodeNumber(buf, number)
+	buf.WriteString("}")
+	return nil
+}
+
+func encodeArrayBuffer(buf *bytes.Buffer, value safejs.Value) error {
+	b, err := idb.BytesFromJS(safejs.Unsafe(value))
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`{"$arrayBuffer":"`)
+	buf.WriteString(base64.StdEncoding.EncodeToString(b))
+	buf.WriteString(`"}`)
+	return nil
+}
+
+func encodeView(buf *bytes.Buffer, value safejs.Value) error {
+	constructor, err := value.Get("constructor")
+	if err != nil {
+		return err
+	}
+	name, err := constructor.Get("name")
+	if err != nil {
+		return err
+	}
+	nameStr, err := name.String()
+	if err != nil {
+		return err
+	}
+	if !viewTypes[nameStr] {
+		return fmt.Errorf("%w: unknown view type %q", errUnsupportedValue, nameStr)
+	}
+	b, err := idb.BytesFromJS(safejs.Unsafe(value))
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`{"$view":`)
+	if err := encodeString(buf, nameStr); err != nil {
+		return err
+	}
+	buf.WriteString(`,"data":"`)
+	buf.WriteString(base64.StdEncoding.EncodeToString(b))
+	buf.WriteString(`"}`)
+	return nil
+}
+
+func encodeMap(buf *bytes.Buffer, value safejs.Value) error {
+	entries, err := jsArray.Call("from", value)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`{"$map":`)
+	if err := encodeArray(buf, entries); err != nil {
+		return err
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+func encodeSet(buf *bytes.Buffer, value safejs.Value) error {
+	elems, err := jsArray.Call("from", value)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`{"$set":`)
+	if err := encodeArray(buf, elems); err != nil {
+		return err
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+func encodeBlob(*bytes.Buffer, safejs.Value) error {
+	return fmt.Errorf("%w: Blob and File values can't be read synchronously", errUnsupportedValue)
+}
+
+func encodeArray(buf *bytes.Buffer, value safejs.Value) error {
+	length, err := value.Length()
+	if err != nil {
+		return err
+	}
+	buf.WriteString("[")
+	for i := 0; i < length; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		elem, err := value.Index(i)
+		if err != nil {
+			return err
+		}
+		if err := encodeValue(buf, elem); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("]")
+	return nil
+}
+
+func encodePlainObject(buf *bytes.Buffer, value safejs.Value) error {
+	jsKeys, err := jsObject.Call("keys", value)
+	if err != nil {
+		return err
+	}
+	length, err := jsKeys.Length()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, length)
+	escape := false
+	for i := 0; i < length; i++ {
+		jsKey, err := jsKeys.Index(i)
+		if err != nil {
+			return err
+		}
+		key, err := jsKey.String()
+		if err != nil {
+			return err
+		}
+		escape = escape || strings.HasPrefix(key, "$")
+		keys = append(keys, key)
+	}
+
+	if escape {
+		buf.WriteString(`{"$object":`)
+	}
+	buf.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if err := encodeString(buf, key); err != nil {
+			return err
+		}
+		buf.WriteString(":")
+		field, err := value.Get(key)
+		if err != nil {
+			return err
+		}
+		if err := encodeValue(buf, field); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("}")
+	if escape {
+		buf.WriteString("}")
+	}
+	return nil
+}
+
+// decodeValue decodes a value encoded by encodeValue
+func decodeValue(data []byte) (safejs.Value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeNext(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return safejs.Value{}, errors.New("backup: unexpected data after value")
+	}
+	return value, nil
+}
+
+func decodeNext(decoder *json.Decoder) (safejs.Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	switch token := token.(type) {
+	case nil:
+		return safejs.Null(), nil
+	case bool, string:
+		return safejs.ValueOf(token)
+	case json.Number:
+		number, err := token.Float64()
+		if err != nil {
+			return safejs.Value{}, err
+		}
+		return safejs.ValueOf(number)
+	case json.Delim:
+		if token == '[' {
+			return decodeArray(decoder)
+		}
+		return decodeObject(decoder)
+	default:
+		return safejs.Value{}, fmt.Errorf("backup: unexpected JSON token: %v", token)
+	}
+}
+
+func decodeArray(decoder *json.Decoder) (safejs.Value, error) {
+	array, err := jsArray.New()
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	for decoder.More() {
+		elem, err := decodeNext(decoder)
+		if err != nil {
+			return safejs.Value{}, err
+		}
+		if _, err := array.Call("push", elem); err != nil {
+			return safejs.Value{}, err
+		}
+	}
+	_, err = decoder.Token() // ]
+	return array, err
+}
+
+// decodeObject decodes an object after its opening brace, including typed values
+func decodeObject(decoder *json.Decoder) (safejs.Value, error) {
+	if !decoder.More() {
+		_, err := decoder.Token() // }
+		if err != nil {
+			return safejs.Value{}, err
+		}
+		return jsObject.New()
+	}
+	key, err := decodeKey(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	if !strings.HasPrefix(key, "$") {
+		return decodeFields(decoder, key, true)
+	}
+
+	var value safejs.Value
+	switch key {
+	case "$undefined":
+		_, err = decoder.Token()
+		value = safejs.Undefined()
+	case "$number":
+		value, err = decodeSpecialNumber(decoder)
+	case "$date":
+		value, err = decodeDate(decoder)
+	case "$arrayBuffer":
+		var array safejs.Value
+		array, err = decodeBytes(decoder)
+		if err == nil {
+			value, err = array.Get("buffer")
+		}
+	case "$view":
+		value, err = decodeView(decoder)
+	case "$map", "$set":
+		var elems safejs.Value
+		elems, err = decodeNext(decoder)
+		if err == nil && key == "$map" {
+			value, err = jsMap.New(elems)
+		} else if err == nil {
+			value, err = jsSet.New(elems)
+		}
+	case "$object":
+		var token json.Token
+		token, err = decoder.Token()
+		if err == nil && token != json.Delim('{') {
+			err = fmt.Errorf("backup: expected object, found: %v", token)
+		}
+		if err == nil {
+			value, err = decodeFields(decoder, "", false)
+		}
+	default:
+		err = fmt.Errorf("backup: unknown value type: %s", key)
+	}
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	_, err = decoder.Token() // }
+	return value, err
+}
+
+// decodeFields decodes a plain object's fields, starting with the value for firstKey if hasFirstKey is true, through its closing brace
+func decodeFields(decoder *json.Decoder, firstKey string, hasFirstKey bool) (safejs.Value, error) {
+	object, err := jsObject.New()
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	key, hasKey := firstKey, hasFirstKey
+	for hasKey || decoder.More() {
+		if !hasKey {
+			key, err = decodeKey(decoder)
+			if err != nil {
+				return safejs.Value{}, err
+			}
+		}
+		hasKey = false
+		field, err := decodeNext(decoder)
+		if err != nil {
+			return safejs.Value{}, err
+		}
+		if err := object.Set(key, field); err != nil {
+			return safejs.Value{}, err
+		}
+	}
+	_, err = decoder.Token() // }
+	return object, err
+}
+
+func decodeKey(decoder *json.Decoder) (string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("backup: expected object key, found: %v", token)
+	}
+	return key, nil
+}
+
+func decodeString(decoder *json.Decoder) (string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	str, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("backup: expected string, found: %v", token)
+	}
+	return str, nil
+}
+
+func decodeSpecialNumber(decoder *json.Decoder) (safejs.Value, error) {
+	str, err := decodeString(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	switch str {
+	case "NaN":
+		return safejs.ValueOf(math.NaN())
+	case "Infinity":
+		return safejs.ValueOf(math.Inf(1))
+	case "-Infinity":
+		return safejs.ValueOf(math.Inf(-1))
+	default:
+		return safejs.Value{}, fmt.Errorf("backup: invalid number: %q", str)
+	}
+}
+
+func decodeDate(decoder *json.Decoder) (safejs.Value, error) {
+	millis, err := decodeNext(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	return jsDate.New(millis)
+}
+
+func decodeBytes(decoder *json.Decoder) (safejs.Value, error) {
+	str, err := decodeString(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	b, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	array, err := idb.BytesToJS(b)
+	return safejs.Safe(array), err
+}
+
+func decodeView(decoder *json.Decoder) (safejs.Value, error) {
+	name, err := decodeString(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	key, err := decodeKey(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	if key != "data" {
+		return safejs.Value{}, fmt.Errorf("backup: expected view data, found: %s", key)
+	}
+	array, err := decodeBytes(decoder)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	if !viewTypes[name] {
+		return safejs.Value{}, fmt.Errorf("%w: unknown view type %q", errUnsupportedValue, name)
+	}
+	if name == "Uint8Array" {
+		return array, nil
+	}
+	constructor, err := safejs.Global().Get(name)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	if constructor.Type() != safejs.TypeFunction {
+		return safejs.Value{}, fmt.Errorf("%w: view type %q is not supported here", errUnsupportedValue, name)
+	}
+	buffer, err := array.Get("buffer")
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	return constructor.New(buffer)
+}
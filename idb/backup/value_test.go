@@ -0,0 +1,97 @@
+//go:build js && wasm
+// +build js,wasm
+
+package backup
+
+import (
+	"bytes"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func evalJS(expr string) js.Value {
+	return js.Global().Get("Function").New("return " + expr).Invoke()
+}
+
+func testEncode(tb testing.TB, value js.Value) string {
+	tb.Helper()
+	var buf bytes.Buffer
+	assert.NoError(tb, encodeValue(&buf, safejs.Safe(value)))
+	return buf.String()
+}
+
+func TestValueRoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		expr     string
+		expected string
+	}{
+		{expr: `null`, expected: `null`},
+		{expr: `undefined`, expected: `{"$undefined":true}`},
+		{expr: `true`, expected: `true`},
+		{expr: `1.5`, expected: `1.5`},
+		{expr: `NaN`, expected: `{"$number":"NaN"}`},
+		{expr: `-Infinity`, expected: `{"$number":"-Infinity"}`},
+		{expr: `"hi\n"`, expected: `"hi\n"`},
+		{expr: `new Date(1000)`, expected: `{"$date":1000}`},
+		{expr: `new Date(NaN)`, expected: `{"$date":{"$number":"NaN"}}`},
+		{expr: `new Uint8Array([1, 2]).buffer`, expected: `{"$arrayBuffer":"AQI="}`},
+		{expr: `new Uint8Array([1, 2])`, expected: `{"$view":"Uint8Array","data":"AQI="}`},
+		{expr: `new Uint16Array([1, 2])`, expected: `{"$view":"Uint16Array","data":"AQACAA=="}`},
+		{expr: `new DataView(new Uint8Array([1, 2]).buffer)`, expected: `{"$view":"DataView","data":"AQI="}`},
+		{expr: `new Map([["a", 1]])`, expected: `{"$map":[["a",1]]}`},
+		{expr: `new Set([1, "b"])`, expected: `{"$set":[1,"b"]}`},
+		{expr: `[1, undefined, "c"]`, expected: `[1,{"$undefined":true},"c"]`},
+		{expr: `({})`, expected: `{}`},
+		{expr: `({b: 1, a: [true]})`, expected: `{"b":1,"a":[true]}`},
+		{expr: `({"": 1, a: 2})`, expected: `{"":1,"a":2}`},
+		{expr: `({a: 1, "": {"": 2}})`, expected: `{"a":1,"":{"":2}}`},
+		{expr: `({$ref: "x", "": 1})`, expected: `{"$object":{"$ref":"x","":1}}`},
+		{expr: `({$ref: "x", nested: {$y: 1}})`, expected: `{"$object":{"$ref":"x","nested":{"$object":{"$y":1}}}}`},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+			encoded := testEncode(t, evalJS(tc.expr))
+			assert.Equal(t, tc.expected, encoded)
+
+			decoded, err := decodeValue([]byte(encoded))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, testEncode(t, safejs.Unsafe(decoded)))
+		})
+	}
+}
+
+func TestEncodeUnsupportedValue(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	err := encodeValue(&buf, safejs.Safe(evalJS(`new Blob(["a"])`)))
+	assert.ErrorIs(t, err, errUnsupportedValue)
+	err = encodeValue(&buf, safejs.Safe(evalJS(`Symbol("a")`)))
+	assert.ErrorIs(t, err, errUnsupportedValue)
+}
+
+func TestDecodeInvalidValue(t *testing.T) {
+	t.Parallel()
+	for _, encoded := range []string{
+		``,
+		`{"$unknown":1}`,
+		`{"$number":"one"}`,
+		`{"$view":"NotAView","data":""}`,
+		`1 2`,
+	} {
+		_, err := decodeValue([]byte(encoded))
+		assert.Error(t, err)
+	}
+}
+
+func TestDecodeUnsupportedView(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"NotAView", "Function", "WebAssembly", "ArrayBuffer"} {
+		_, err := decodeValue([]byte(`{"$view":"` + name + `","data":"AQI="}`))
+		assert.ErrorIs(t, err, errUnsupportedValue)
+	}
+}
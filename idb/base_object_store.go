@@ -45,7 +45,11 @@ func (b *baseObjectStore) CountKey(key safejs.Value) (*UintRequest, error) {
 
 // CountRange returns a UintRequest, and, in a separate thread, returns the total number of records that match the provided KeyRange.
 func (b *baseObjectStore) CountRange(keyRange *KeyRange) (*UintRequest, error) {
-	reqValue, err := b.jsObjectStore.Call("count", keyRange.jsKeyRange)
+	jsKeyRange, err := keyRange.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	reqValue, err := b.jsObjectStore.Call("count", jsKeyRange)
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
@@ -65,7 +69,11 @@ func (b *baseObjectStore) GetAllKeys() (*ArrayRequest, error) {
 
 // GetAllKeysRange returns an ArrayRequest that retrieves record keys for all objects in the object store or index matching the specified query. If maxCount is 0, retrieves all objects matching the query.
 func (b *baseObjectStore) GetAllKeysRange(query *KeyRange, maxCount uint) (*ArrayRequest, error) {
-	args := []interface{}{query.jsKeyRange}
+	jsKeyRange, err := query.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{jsKeyRange}
 	if maxCount > 0 {
 		args = append(args, maxCount)
 	}
@@ -117,7 +125,11 @@ func (b *baseObjectStore) OpenCursorKey(key safejs.Value, direction CursorDirect
 
 // OpenCursorRange is the same as OpenCursor, but opens a cursor over the given range instead.
 func (b *baseObjectStore) OpenCursorRange(keyRange *KeyRange, direction CursorDirection) (*CursorWithValueRequest, error) {
-	reqValue, err := b.jsObjectStore.Call("openCursor", keyRange.jsKeyRange, direction.jsValue())
+	jsKeyRange, err := keyRange.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	reqValue, err := b.jsObjectStore.Call("openCursor", jsKeyRange, direction.jsValue())
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
@@ -147,7 +159,11 @@ func (b *baseObjectStore) OpenKeyCursorKey(key safejs.Value, direction CursorDir
 
 // OpenKeyCursorRange is the same as OpenKeyCursor, but opens a cursor over the given key range instead.
 func (b *baseObjectStore) OpenKeyCursorRange(keyRange *KeyRange, direction CursorDirection) (*CursorRequest, error) {
-	reqValue, err := b.jsObjectStore.Call("openKeyCursor", keyRange.jsKeyRange, direction.jsValue())
+	jsKeyRange, err := keyRange.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	reqValue, err := b.jsObjectStore.Call("openKeyCursor", jsKeyRange, direction.jsValue())
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
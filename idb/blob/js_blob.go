@@ -0,0 +1,220 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/promise"
+	"github.com/hack-pad/safejs"
+)
+
+// readPartSize is the size of each part read by NewJSBlobFromReader
+const readPartSize = 64 * 1024
+
+var (
+	jsBlob  safejs.Value
+	jsArray safejs.Value
+
+	errNotBlob = errors.New("blob: value is not a Blob")
+)
+
+func init() {
+	var err error
+	jsBlob, err = safejs.Global().Get("Blob")
+	if err != nil {
+		panic(err)
+	}
+	jsArray, err = safejs.Global().Get("Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// JSBlob is a JavaScript Blob or File. IndexedDB stores Blobs directly, so a JSBlob's Value can be passed to ObjectStore.Put and friends.
+type JSBlob struct {
+	jsBlob safejs.Value
+}
+
+// NewJSBlob creates a new Blob containing a copy of b. contentType is the Blob's MIME type, or empty if unknown.
+func NewJSBlob(b []byte, contentType string) (*JSBlob, error) {
+	return NewJSBlobFromReader(bytes.NewReader(b), contentType)
+}
+
+// NewJSBlobFromReader creates a new Blob containing everything read from r. contentType is the Blob's MIME type, or empty if unknown.
+// Data is copied into JavaScript in parts as it's read, so r's contents are never buffered in Go all at once.
+func NewJSBlobFromReader(r io.Reader, contentType string) (*JSBlob, error) {
+	parts, err := jsArray.New()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, readPartSize)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			part, err := idb.BytesToJS(buf[:n])
+			if err != nil {
+				return nil, err
+			}
+			if _, err := parts.Call("push", part); err != nil {
+				return nil, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	blob, err := jsBlob.New(parts, map[string]interface{}{
+		"type": contentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &JSBlob{jsBlob: blob}, nil
+}
+
+// WrapJSBlob wraps a Blob or File value, like one returned by Request.Result or CursorWithValue.Value. Returns an error if value is not a Blob.
+func WrapJSBlob(value js.Value) (*JSBlob, error) {
+	isBlob, err := safejs.Safe(value).InstanceOf(jsBlob)
+	if err != nil {
+		return nil, err
+	}
+	if !isBlob {
+		return nil, errNotBlob
+	}
+	return &JSBlob{jsBlob: safejs.Safe(value)}, nil
+}
+
+// Value returns the underlying Blob value
+func (b *JSBlob) Value() js.Value {
+	return safejs.Unsafe(b.jsBlob)
+}
+
+// Size returns the Blob's size in bytes
+func (b *JSBlob) Size() (int64, error) {
+	size, err := b.jsBlob.Get("size")
+	if err != nil {
+		return 0, err
+	}
+	sizeFloat, err := size.Float()
+	return int64(sizeFloat), err
+}
+
+// Type returns the Blob's MIME type, or an empty string if unknown
+func (b *JSBlob) Type() (string, error) {
+	contentType, err := b.jsBlob.Get("type")
+	if err != nil {
+		return "", err
+	}
+	return contentType.String()
+}
+
+// Name returns the File's name, or an empty string if the Blob is not a File
+func (b *JSBlob) Name() (string, error) {
+	name, err := b.jsBlob.Get("name")
+	if err != nil || name.IsUndefined() {
+		return "", err
+	}
+	return name.String()
+}
+
+// Bytes reads the Blob's entire contents
+func (b *JSBlob) Bytes(ctx context.Context) ([]byte, error) {
+	arrayBufferPromise, err := b.jsBlob.Call("arrayBuffer")
+	if err != nil {
+		return nil, err
+	}
+	arrayBuffer, err := promise.Await(ctx, arrayBufferPromise)
+	if err != nil {
+		return nil, err
+	}
+	return idb.BytesFromJS(safejs.Unsafe(arrayBuffer))
+}
+
+// Reader returns a reader which streams the Blob's contents, one part at a time. ctx is used for every read.
+// If the browser does not support Blob streams, the entire contents are read when Reader is called.
+func (b *JSBlob) Reader(ctx context.Context) (io.ReadCloser, error) {
+	stream, err := b.jsBlob.Get("stream")
+	if err != nil {
+		return nil, err
+	}
+	if stream.IsUndefined() {
+		contents, err := b.Bytes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(contents)), nil
+	}
+	jsStream, err := b.jsBlob.Call("stream")
+	if err != nil {
+		return nil, err
+	}
+	jsReader, err := jsStream.Call("getReader")
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{ctx: ctx, jsReader: jsReader}, nil
+}
+
+// streamReader reads from a ReadableStream's default reader
+type streamReader struct {
+	ctx      context.Context
+	jsReader safejs.Value
+	buf      []byte
+	done     bool
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.readPart(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// readPart waits for the stream's next part and buffers it
+func (s *streamReader) readPart() error {
+	readPromise, err := s.jsReader.Call("read")
+	if err != nil {
+		return err
+	}
+	result, err := promise.Await(s.ctx, readPromise)
+	if err != nil {
+		return err
+	}
+	done, err := result.Get("done")
+	if err != nil {
+		return err
+	}
+	s.done, err = done.Bool()
+	if err != nil || s.done {
+		return err
+	}
+	value, err := result.Get("value")
+	if err != nil {
+		return err
+	}
+	s.buf, err = idb.BytesFromJS(safejs.Unsafe(value))
+	return err
+}
+
+func (s *streamReader) Close() error {
+	s.done, s.buf = true, nil
+	_, err := s.jsReader.Call("cancel")
+	return err
+}
@@ -0,0 +1,103 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestNewJSBlob(t *testing.T) {
+	t.Parallel()
+	blob, err := NewJSBlob([]byte("hello world"), "text/plain")
+	assert.NoError(t, err)
+	size, err := blob.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+	contentType, err := blob.Type()
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+	name, err := blob.Name()
+	assert.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	contents, err := blob.Bytes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(contents))
+}
+
+func TestNewJSBlobFromReader(t *testing.T) {
+	t.Parallel()
+	expected := bytes.Repeat([]byte("0123456789"), readPartSize/5) // spans multiple parts
+	blob, err := NewJSBlobFromReader(bytes.NewReader(expected), "")
+	assert.NoError(t, err)
+	size, err := blob.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(expected)), size)
+
+	contents, err := blob.Bytes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, true, bytes.Equal(expected, contents))
+}
+
+func TestWrapJSBlob(t *testing.T) {
+	t.Parallel()
+	_, err := WrapJSBlob(js.ValueOf("not a blob"))
+	assert.ErrorIs(t, err, errNotBlob)
+
+	blob, err := NewJSBlob([]byte("hello"), "")
+	assert.NoError(t, err)
+	wrapped, err := WrapJSBlob(blob.Value())
+	assert.NoError(t, err)
+	contents, err := wrapped.Bytes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(contents))
+}
+
+func TestJSBlobReader(t *testing.T) {
+	t.Parallel()
+	expected := bytes.Repeat([]byte("0123456789"), 100000)
+	blob, err := NewJSBlob(expected, "")
+	assert.NoError(t, err)
+	reader, err := blob.Reader(context.Background())
+	assert.NoError(t, err)
+	contents, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, true, bytes.Equal(expected, contents))
+}
+
+func TestJSBlobStoredValue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	blob, err := NewJSBlob([]byte("hello world"), "text/plain")
+	assert.NoError(t, err)
+
+	txn, err := store.Database().Transaction(idb.TransactionReadWrite, blobsStore)
+	assert.NoError(t, err)
+	blobs, err := txn.ObjectStore(blobsStore)
+	assert.NoError(t, err)
+	_, err = blobs.PutKey(js.ValueOf("myblob"), blob.Value())
+	assert.NoError(t, err)
+	req, err := blobs.Get(js.ValueOf("myblob"))
+	assert.NoError(t, err)
+	value, err := req.Await(ctx)
+	assert.NoError(t, err)
+
+	stored, err := WrapJSBlob(value)
+	assert.NoError(t, err)
+	contentType, err := stored.Type()
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", contentType)
+	contents, err := stored.Bytes(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(contents))
+}
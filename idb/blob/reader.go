@@ -0,0 +1,112 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+)
+
+var errNegativeOffset = errors.New("blob: negative offset")
+
+// Reader reads a blob's contents. Each Read fetches only the chunk containing the current offset, so seeking skips over chunks without reading them.
+type Reader struct {
+	ctx        context.Context
+	store      *Store
+	info       Info
+	offset     int64
+	chunkIndex int
+	chunk      []byte
+}
+
+var _ io.ReadSeeker = &Reader{}
+
+// Open returns a Reader for the blob with the given ID. Returns ErrNotFound if it does not exist. ctx is used for every read.
+//
+// Replacing or deleting the blob while it is being read causes later reads to fail.
+func (s *Store) Open(ctx context.Context, id string) (*Reader, error) {
+	info, err := s.Stat(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		ctx:        ctx,
+		store:      s,
+		info:       info,
+		chunkIndex: -1,
+	}, nil
+}
+
+// Info returns info about the blob being read
+func (r *Reader) Info() Info {
+	return r.info
+}
+
+// Size returns the blob's size in bytes
+func (r *Reader) Size() int64 {
+	return r.info.Size
+}
+
+// Read implements io.Reader. Reads at most to the end of the current chunk. Returns ErrChecksum if the chunk is corrupted.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.info.Size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	chunkSize := int64(r.info.ChunkSize)
+	index := int(r.offset / chunkSize)
+	if err := r.loadChunk(index); err != nil {
+		return 0, err
+	}
+	start := int(r.offset - int64(index)*chunkSize)
+	if start >= len(r.chunk) {
+		return 0, fmt.Errorf("blob: chunk %d of %q is too short: %w", index, r.info.ID, io.ErrUnexpectedEOF)
+	}
+	n := copy(p, r.chunk[start:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// loadChunk fetches and verifies chunk index, unless it is already loaded
+func (r *Reader) loadChunk(index int) error {
+	if index == r.chunkIndex {
+		return nil
+	}
+	err := txnutil.Run(r.ctx, r.store.db, idb.TransactionReadOnly, []string{chunksStore}, func(txn *idb.Transaction) error {
+		var err error
+		r.chunk, err = getChunk(r.ctx, txn, r.info.ID, index)
+		return err
+	})
+	if err != nil {
+		r.chunkIndex, r.chunk = -1, nil
+		return err
+	}
+	r.chunkIndex = index
+	return nil
+}
+
+// Seek implements io.Seeker. Seeking past the end is allowed, and later reads return io.EOF.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.info.Size
+	default:
+		return 0, fmt.Errorf("blob: invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, errNegativeOffset
+	}
+	r.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,72 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestReaderSeek(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	_, err := store.Put(ctx, "myblob", bytes.NewReader([]byte("0123456789")))
+	assert.NoError(t, err)
+	reader, err := store.Open(ctx, "myblob")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), reader.Size())
+
+	for _, tc := range []struct {
+		offset    int64
+		whence    int
+		expectPos int64
+		expect    string
+	}{
+		{offset: 6, whence: io.SeekStart, expectPos: 6, expect: "67"},
+		{offset: -5, whence: io.SeekCurrent, expectPos: 3, expect: "3"},
+		{offset: -2, whence: io.SeekEnd, expectPos: 8, expect: "89"},
+		{offset: 2, whence: io.SeekEnd, expectPos: 12, expect: ""},
+	} {
+		pos, err := reader.Seek(tc.offset, tc.whence)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectPos, pos)
+		buf := make([]byte, 10)
+		n, err := reader.Read(buf)
+		if tc.expect == "" {
+			assert.ErrorIs(t, err, io.EOF)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, tc.expect, string(buf[:n])) // reads stop at the end of each chunk
+	}
+
+	_, err = reader.Seek(-1, io.SeekStart)
+	assert.ErrorIs(t, err, errNegativeOffset)
+}
+
+func TestReaderOpenMissing(t *testing.T) {
+	t.Parallel()
+	store := testStore(t)
+	_, err := store.Open(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestReaderDeleted(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	_, err := store.Put(ctx, "myblob", bytes.NewReader([]byte("hello world")))
+	assert.NoError(t, err)
+	reader, err := store.Open(ctx, "myblob")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete(ctx, "myblob"))
+
+	_, err = io.ReadAll(reader)
+	assert.Error(t, err)
+}
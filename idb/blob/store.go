@@ -0,0 +1,302 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package blob stores large binary objects in IndexedDB, split into fixed-size chunks.
+//
+// Each chunk is stored in its own record, keyed by [blobID, chunkIndex], with a CRC-32 checksum of its data. Writers upload one chunk per transaction and readers fetch only the chunks they need, so neither side holds the whole blob in memory.
+//
+// For smaller values, JSBlob stores a JavaScript Blob or File directly as an IndexedDB value.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// DefaultChunkSize is the chunk size used when Options.ChunkSize is not set
+	DefaultChunkSize = 256 * 1024
+
+	blobsStore  = "blobs"
+	chunksStore = "chunks"
+)
+
+var (
+	// ErrNotFound is returned when opening a blob which does not exist
+	ErrNotFound = errors.New("blob: not found")
+	// ErrChecksum is returned when a chunk's data does not match its checksum
+	ErrChecksum = errors.New("blob: chunk checksum mismatch")
+)
+
+// Options contains all available options for opening a Store
+type Options struct {
+	// ChunkSize is the maximum size of each chunk in bytes for new blobs. Defaults to DefaultChunkSize.
+	// Existing blobs keep the chunk size they were written with.
+	ChunkSize int
+}
+
+// Store is a chunked blob store in an IndexedDB database
+type Store struct {
+	db        *idb.Database
+	chunkSize int
+}
+
+// Info describes a stored blob
+type Info struct {
+	ID        string
+	Size      int64
+	ChunkSize int
+}
+
+// Chunks returns the number of chunks in the blob
+func (i Info) Chunks() int {
+	return int((i.Size + int64(i.ChunkSize) - 1) / int64(i.ChunkSize))
+}
+
+// Open opens the blob store in the global IndexedDB database named dbName, creating it if it does not exist
+func Open(ctx context.Context, dbName string, options Options) (*Store, error) {
+	return OpenFactory(ctx, idb.Global(), dbName, options)
+}
+
+// OpenFactory is the same as Open, but opens the database in the given factory. Useful for storage buckets.
+func OpenFactory(ctx context.Context, factory *idb.Factory, dbName string, options Options) (*Store, error) {
+	if options.ChunkSize < 0 {
+		return nil, fmt.Errorf("blob: invalid chunk size: %d", options.ChunkSize)
+	}
+	if options.ChunkSize == 0 {
+		options.ChunkSize = DefaultChunkSize
+	}
+	req, err := factory.Open(ctx, dbName, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore(blobsStore, idb.ObjectStoreOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = db.CreateObjectStore(chunksStore, idb.ObjectStoreOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db, chunkSize: options.ChunkSize}, nil
+}
+
+// Database returns the database containing this store
+func (s *Store) Database() *idb.Database {
+	return s.db
+}
+
+// Close closes the store's database connection
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Stat returns info about the blob with the given ID. Returns ErrNotFound if it does not exist.
+func (s *Store) Stat(ctx context.Context, id string) (Info, error) {
+	var info Info
+	err := txnutil.Run(ctx, s.db, idb.TransactionReadOnly, []string{blobsStore}, func(txn *idb.Transaction) error {
+		var err error
+		info, err = getInfo(ctx, txn, id)
+		return err
+	})
+	return info, err
+}
+
+// IDs returns the IDs of all stored blobs in sorted order
+func (s *Store) IDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := txnutil.Run(ctx, s.db, idb.TransactionReadOnly, []string{blobsStore}, func(txn *idb.Transaction) error {
+		blobs, err := txn.ObjectStore(blobsStore)
+		if err != nil {
+			return err
+		}
+		req, err := blobs.GetAllKeys()
+		if err != nil {
+			return err
+		}
+		jsIDs, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		ids = make([]string, 0, len(jsIDs))
+		for _, jsID := range jsIDs {
+			id, err := safejs.Safe(jsID).String()
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// Delete deletes the blob with the given ID and all of its chunks in a single transaction. Does nothing if it does not exist.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	return txnutil.Run(ctx, s.db, idb.TransactionReadWrite, []string{blobsStore, chunksStore}, func(txn *idb.Transaction) error {
+		return deleteBlob(txn, id)
+	})
+}
+
+// deleteBlob deletes the blob's info and all of its chunks
+func deleteBlob(txn *idb.Transaction, id string) error {
+	blobs, err := txn.ObjectStore(blobsStore)
+	if err != nil {
+		return err
+	}
+	jsID, err := jsString(id)
+	if err != nil {
+		return err
+	}
+	if _, err := blobs.Delete(jsID); err != nil {
+		return err
+	}
+	chunks, err := txn.ObjectStore(chunksStore)
+	if err != nil {
+		return err
+	}
+	keyRange, err := idb.NewKeyRangeCompoundPrefix(id)
+	if err != nil {
+		return err
+	}
+	_, err = chunks.DeleteRange(keyRange)
+	return err
+}
+
+func getInfo(ctx context.Context, txn *idb.Transaction, id string) (Info, error) {
+	blobs, err := txn.ObjectStore(blobsStore)
+	if err != nil {
+		return Info{}, err
+	}
+	jsID, err := jsString(id)
+	if err != nil {
+		return Info{}, err
+	}
+	req, err := blobs.Get(jsID)
+	if err != nil {
+		return Info{}, err
+	}
+	value, err := req.Await(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	if safejs.Safe(value).IsUndefined() {
+		return Info{}, ErrNotFound
+	}
+	info := Info{ID: id}
+	size, err := getInt(safejs.Safe(value), "size")
+	if err != nil {
+		return Info{}, err
+	}
+	info.Size = int64(size)
+	info.ChunkSize, err = getInt(safejs.Safe(value), "chunkSize")
+	return info, err
+}
+
+func putInfo(txn *idb.Transaction, info Info) error {
+	blobs, err := txn.ObjectStore(blobsStore)
+	if err != nil {
+		return err
+	}
+	jsID, err := jsString(info.ID)
+	if err != nil {
+		return err
+	}
+	value, err := safejs.ValueOf(map[string]interface{}{
+		"size":      info.Size,
+		"chunkSize": info.ChunkSize,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = blobs.PutKey(jsID, safejs.Unsafe(value))
+	return err
+}
+
+// getChunk returns the verified data of chunk index in blob id
+func getChunk(ctx context.Context, txn *idb.Transaction, id string, index int) ([]byte, error) {
+	chunks, err := txn.ObjectStore(chunksStore)
+	if err != nil {
+		return nil, err
+	}
+	key, err := idb.NewCompoundKey(id, index)
+	if err != nil {
+		return nil, err
+	}
+	req, err := chunks.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if safejs.Safe(value).IsUndefined() {
+		return nil, fmt.Errorf("blob: chunk %d of %q is missing", index, id)
+	}
+	jsData, err := safejs.Safe(value).Get("data")
+	if err != nil {
+		return nil, err
+	}
+	data, err := idb.BytesFromJS(safejs.Unsafe(jsData))
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := getInt(safejs.Safe(value), "crc32")
+	if err != nil {
+		return nil, err
+	}
+	if uint32(checksum) != crc32.ChecksumIEEE(data) {
+		return nil, fmt.Errorf("%w: chunk %d of %q", ErrChecksum, index, id)
+	}
+	return data, nil
+}
+
+func putChunk(txn *idb.Transaction, id string, index int, data []byte) error {
+	chunks, err := txn.ObjectStore(chunksStore)
+	if err != nil {
+		return err
+	}
+	key, err := idb.NewCompoundKey(id, index)
+	if err != nil {
+		return err
+	}
+	jsData, err := idb.BytesToJS(data)
+	if err != nil {
+		return err
+	}
+	value, err := safejs.ValueOf(map[string]interface{}{
+		"crc32": crc32.ChecksumIEEE(data),
+	})
+	if err != nil {
+		return err
+	}
+	if err := value.Set("data", jsData); err != nil {
+		return err
+	}
+	_, err = chunks.PutKey(key, safejs.Unsafe(value))
+	return err
+}
+
+func getInt(value safejs.Value, property string) (int, error) {
+	propertyValue, err := value.Get(property)
+	if err != nil {
+		return 0, err
+	}
+	return propertyValue.Int()
+}
+
+func jsString(s string) (js.Value, error) {
+	value, err := safejs.ValueOf(s)
+	return safejs.Unsafe(value), err
+}
@@ -0,0 +1,126 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+const testChunkSize = 4
+
+func testStore(tb testing.TB) *Store {
+	tb.Helper()
+	store, err := Open(context.Background(), idbtest.DBName(tb), Options{ChunkSize: testChunkSize})
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, store.Close())
+	})
+	return store
+}
+
+func countChunks(tb testing.TB, store *Store, id string) uint {
+	tb.Helper()
+	txn, err := store.Database().Transaction(idb.TransactionReadOnly, chunksStore)
+	assert.NoError(tb, err)
+	chunks, err := txn.ObjectStore(chunksStore)
+	assert.NoError(tb, err)
+	keyRange, err := idb.NewKeyRangeCompoundPrefix(id)
+	assert.NoError(tb, err)
+	req, err := chunks.CountRange(keyRange)
+	assert.NoError(tb, err)
+	count, err := req.Await(context.Background())
+	assert.NoError(tb, err)
+	return count
+}
+
+func TestOpenInvalidChunkSize(t *testing.T) {
+	t.Parallel()
+	_, err := Open(context.Background(), "blob-test/invalid", Options{ChunkSize: -1})
+	assert.Error(t, err)
+}
+
+func TestStoreStat(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	_, err := store.Stat(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = store.Put(ctx, "myblob", bytes.NewReader([]byte("hello world")))
+	assert.NoError(t, err)
+	info, err := store.Stat(ctx, "myblob")
+	assert.NoError(t, err)
+	assert.Equal(t, Info{ID: "myblob", Size: 11, ChunkSize: testChunkSize}, info)
+	assert.Equal(t, 3, info.Chunks())
+}
+
+func TestStoreIDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	for _, id := range []string{"b", "a", "c"} {
+		_, err := store.Put(ctx, id, bytes.NewReader([]byte(id)))
+		assert.NoError(t, err)
+	}
+	ids, err := store.IDs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+}
+
+func TestStoreDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	_, err := store.Put(ctx, "myblob", bytes.NewReader([]byte("hello world")))
+	assert.NoError(t, err)
+	_, err = store.Put(ctx, "myblob2", bytes.NewReader([]byte("other")))
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Delete(ctx, "myblob"))
+	assert.NoError(t, store.Delete(ctx, "myblob"))
+	_, err = store.Stat(ctx, "myblob")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, uint(0), countChunks(t, store, "myblob"))
+	assert.Equal(t, uint(2), countChunks(t, store, "myblob2"))
+}
+
+func TestStoreChecksum(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	_, err := store.Put(ctx, "myblob", bytes.NewReader([]byte("hello world")))
+	assert.NoError(t, err)
+
+	// corrupt the second chunk without updating its checksum
+	txn, err := store.Database().Transaction(idb.TransactionReadWrite, chunksStore)
+	assert.NoError(t, err)
+	chunks, err := txn.ObjectStore(chunksStore)
+	assert.NoError(t, err)
+	key, err := idb.NewCompoundKey("myblob", 1)
+	assert.NoError(t, err)
+	req, err := chunks.Get(key)
+	assert.NoError(t, err)
+	value, err := req.Await(ctx)
+	assert.NoError(t, err)
+	value.Get("data").SetIndex(0, 'X')
+	_, err = chunks.PutKey(key, value)
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	reader, err := store.Open(ctx, "myblob")
+	assert.NoError(t, err)
+	buf := make([]byte, testChunkSize)
+	_, err = reader.Read(buf)
+	assert.NoError(t, err)
+	_, err = reader.Read(buf)
+	assert.ErrorIs(t, err, ErrChecksum)
+}
@@ -0,0 +1,135 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+)
+
+var errWriterClosed = errors.New("blob: writer is closed")
+
+// Writer uploads a blob one chunk at a time. Each full chunk is saved in its own transaction, so at most one chunk is buffered in memory.
+// The blob is only visible to Stat and Open after Close succeeds.
+type Writer struct {
+	ctx       context.Context
+	store     *Store
+	id        string
+	buf       []byte
+	chunks    int
+	size      int64
+	err       error
+	closed    bool
+	chunkSize int
+}
+
+var _ io.WriteCloser = &Writer{}
+
+// Create deletes any existing blob with the given ID, then returns a Writer to upload its new contents. ctx is used for every write.
+func (s *Store) Create(ctx context.Context, id string) (*Writer, error) {
+	err := txnutil.Run(ctx, s.db, idb.TransactionReadWrite, []string{blobsStore, chunksStore}, func(txn *idb.Transaction) error {
+		return deleteBlob(txn, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		ctx:       ctx,
+		store:     s,
+		id:        id,
+		buf:       make([]byte, 0, s.chunkSize),
+		chunkSize: s.chunkSize,
+	}, nil
+}
+
+// Put uploads the contents of r to the blob with the given ID, replacing any existing blob. Returns the number of bytes written.
+// If reading or writing fails, the partially written blob is deleted.
+func (s *Store) Put(ctx context.Context, id string, r io.Reader) (int64, error) {
+	w, err := s.Create(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(w, r)
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		_ = w.Abort()
+		return n, err
+	}
+	return n, nil
+}
+
+// Write buffers p, saving each chunk as it fills up. Once a write fails, all later writes return the same error.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		free := w.chunkSize - len(w.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		w.buf = append(w.buf, p[:free]...)
+		p = p[free:]
+		n += free
+		if len(w.buf) == w.chunkSize {
+			err := txnutil.Run(w.ctx, w.store.db, idb.TransactionReadWrite, []string{chunksStore}, w.putBuffer)
+			if err != nil {
+				w.err = err
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// putBuffer saves the buffered data as the next chunk
+func (w *Writer) putBuffer(txn *idb.Transaction) error {
+	if err := putChunk(txn, w.id, w.chunks, w.buf); err != nil {
+		return err
+	}
+	w.chunks++
+	w.size += int64(len(w.buf))
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close saves the last partial chunk and the blob's info in a single transaction, making the blob visible to readers
+func (w *Writer) Close() error {
+	if w.closed {
+		return errWriterClosed
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	w.err = txnutil.Run(w.ctx, w.store.db, idb.TransactionReadWrite, []string{blobsStore, chunksStore}, func(txn *idb.Transaction) error {
+		if len(w.buf) > 0 {
+			if err := w.putBuffer(txn); err != nil {
+				return err
+			}
+		}
+		return putInfo(txn, Info{ID: w.id, Size: w.size, ChunkSize: w.chunkSize})
+	})
+	return w.err
+}
+
+// Abort stops the upload and deletes any chunks already saved. Does nothing if the Writer was closed successfully.
+func (w *Writer) Abort() error {
+	if w.closed && w.err == nil {
+		return nil
+	}
+	w.closed = true
+	w.buf = nil
+	return w.store.Delete(w.ctx, w.id)
+}
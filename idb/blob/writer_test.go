@@ -0,0 +1,119 @@
+//go:build js && wasm
+// +build js,wasm
+
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		writes []string
+		chunks uint
+	}{
+		{name: "empty", writes: nil, chunks: 0},
+		{name: "partial chunk", writes: []string{"ab"}, chunks: 1},
+		{name: "exact chunks", writes: []string{"abcd", "efgh"}, chunks: 2},
+		{name: "spans chunks", writes: []string{"abc", "defghi", "j"}, chunks: 3},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			store := testStore(t)
+			writer, err := store.Create(ctx, "myblob")
+			assert.NoError(t, err)
+			var expected []byte
+			for _, write := range tc.writes {
+				n, err := writer.Write([]byte(write))
+				assert.NoError(t, err)
+				assert.Equal(t, len(write), n)
+				expected = append(expected, write...)
+			}
+			_, err = store.Stat(ctx, "myblob")
+			assert.ErrorIs(t, err, ErrNotFound) // not visible until closed
+			assert.NoError(t, writer.Close())
+
+			reader, err := store.Open(ctx, "myblob")
+			assert.NoError(t, err)
+			contents, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(contents))
+			assert.Equal(t, tc.chunks, countChunks(t, store, "myblob"))
+		})
+	}
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	writer, err := store.Create(ctx, "myblob")
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	_, err = writer.Write([]byte("abc"))
+	assert.ErrorIs(t, err, errWriterClosed)
+	assert.ErrorIs(t, writer.Close(), errWriterClosed)
+	assert.NoError(t, writer.Abort())
+}
+
+func TestWriterReplace(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	_, err := store.Put(ctx, "myblob", bytes.NewReader([]byte("a long value")))
+	assert.NoError(t, err)
+	_, err = store.Put(ctx, "myblob", bytes.NewReader([]byte("short")))
+	assert.NoError(t, err)
+
+	reader, err := store.Open(ctx, "myblob")
+	assert.NoError(t, err)
+	contents, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "short", string(contents))
+	assert.Equal(t, uint(2), countChunks(t, store, "myblob"))
+}
+
+func TestWriterAbort(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	writer, err := store.Create(ctx, "myblob")
+	assert.NoError(t, err)
+	_, err = writer.Write([]byte("hello world"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Abort())
+
+	_, err = store.Stat(ctx, "myblob")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, uint(0), countChunks(t, store, "myblob"))
+}
+
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
+
+func TestStorePutReadError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	readErr := errors.New("some error")
+	_, err := store.Put(ctx, "myblob", io.MultiReader(bytes.NewReader([]byte("hello world")), errReader{readErr}))
+	assert.ErrorIs(t, err, readErr)
+	_, err = store.Stat(ctx, "myblob")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, uint(0), countChunks(t, store, "myblob"))
+}
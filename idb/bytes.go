@@ -0,0 +1,186 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// maxPooledArraySize is the largest Uint8Array kept in bytesPool
+	maxPooledArraySize = 4 << 20
+	// maxPooledArraysPerSize is the most Uint8Arrays kept in bytesPool for each size
+	maxPooledArraysPerSize = 4
+)
+
+var (
+	errNotBytes = errors.New("Value is not an ArrayBuffer, DataView, or typed array")
+
+	bytesPool = &uint8ArrayPool{arrays: make(map[int][]safejs.Value)}
+)
+
+// uint8ArrayPool reuses Uint8Arrays of the same size. Arrays are only safe to reuse once nothing references them, like after a put request has cloned them.
+type uint8ArrayPool struct {
+	mu     sync.Mutex
+	arrays map[int][]safejs.Value
+}
+
+func (p *uint8ArrayPool) get(size int) (safejs.Value, error) {
+	p.mu.Lock()
+	arrays := p.arrays[size]
+	if len(arrays) > 0 {
+		array := arrays[len(arrays)-1]
+		p.arrays[size] = arrays[:len(arrays)-1]
+		p.mu.Unlock()
+		return array, nil
+	}
+	p.mu.Unlock()
+	return jsUint8Array.New(size)
+}
+
+func (p *uint8ArrayPool) put(size int, array safejs.Value) {
+	if size > maxPooledArraySize {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.arrays[size]) < maxPooledArraysPerSize {
+		p.arrays[size] = append(p.arrays[size], array)
+	}
+}
+
+// BytesToJS returns a new Uint8Array containing a copy of b
+func BytesToJS(b []byte) (js.Value, error) {
+	array, err := jsUint8Array.New(len(b))
+	if err != nil {
+		return js.Value{}, err
+	}
+	_, err = safejs.CopyBytesToJS(array, b)
+	return safejs.Unsafe(array), err
+}
+
+// BytesFromJS returns a copy of the bytes in an ArrayBuffer, DataView, or typed array value, like a Uint8Array
+func BytesFromJS(value js.Value) ([]byte, error) {
+	b, isBuffer, err := bytesFromJSBuffer(safejs.Safe(value))
+	if err != nil {
+		return nil, err
+	}
+	if !isBuffer {
+		return nil, errNotBytes
+	}
+	return b, nil
+}
+
+// withPooledBytes copies b into a pooled Uint8Array, calls fn with it, then returns the array to the pool. fn must not keep a reference to the array.
+func withPooledBytes(b []byte, fn func(array js.Value) error) error {
+	array, err := bytesPool.get(len(b))
+	if err != nil {
+		return err
+	}
+	if _, err := safejs.CopyBytesToJS(array, b); err != nil {
+		return err
+	}
+	err = fn(safejs.Unsafe(array))
+	bytesPool.put(len(b), array)
+	return err
+}
+
+// AddBytes is the same as AddKey, but stores a copy of b as a Uint8Array. key may be undefined for stores with a key generator.
+func (o *ObjectStore) AddBytes(key js.Value, b []byte) (*AckRequest, error) {
+	var req *AckRequest
+	err := withPooledBytes(b, func(array js.Value) error {
+		var err error
+		req, err = o.AddKey(key, array) // add clones the array before returning
+		return err
+	})
+	return req, err
+}
+
+// PutBytes is the same as PutKey, but stores a copy of b as a Uint8Array. key may be undefined for stores with a key generator.
+func (o *ObjectStore) PutBytes(key js.Value, b []byte) (*Request, error) {
+	var req *Request
+	err := withPooledBytes(b, func(array js.Value) error {
+		var err error
+		req, err = o.PutKey(key, array) // put clones the array before returning
+		return err
+	})
+	return req, err
+}
+
+// GetBytes is the same as Get, but returns a BytesRequest which reads binary values
+func (o *ObjectStore) GetBytes(key js.Value) (*BytesRequest, error) {
+	req, err := o.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return newBytesRequest(req), nil
+}
+
+// GetBytes is the same as Get, but returns a BytesRequest which reads binary values
+func (i *Index) GetBytes(key js.Value) (*BytesRequest, error) {
+	req, err := i.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return newBytesRequest(req), nil
+}
+
+// ValueBytes returns a copy of the cursor's current binary value. Supports the same values as BytesFromJS.
+func (c *CursorWithValue) ValueBytes() ([]byte, error) {
+	value, err := c.Value()
+	if err != nil {
+		return nil, err
+	}
+	return BytesFromJS(value)
+}
+
+// UpdateBytes is the same as Update, but stores a copy of b as a Uint8Array
+func (c *Cursor) UpdateBytes(b []byte) (*Request, error) {
+	var req *Request
+	err := withPooledBytes(b, func(array js.Value) error {
+		var err error
+		req, err = c.Update(array) // update clones the array before returning
+		return err
+	})
+	return req, err
+}
+
+// BytesRequest is a Request that retrieves a binary value, stored as an ArrayBuffer, DataView, or typed array
+type BytesRequest struct {
+	*Request
+}
+
+func newBytesRequest(req *Request) *BytesRequest {
+	return &BytesRequest{req}
+}
+
+// Result returns a copy of the result's bytes, or nil if no record was found. If the request failed and the result is not available, an error is returned.
+func (b *BytesRequest) Result() ([]byte, error) {
+	result, err := b.Request.result()
+	if err != nil {
+		return nil, err
+	}
+	return bytesFromResult(result)
+}
+
+// Await waits for success or failure, then returns a copy of the result's bytes, or nil if no record was found
+func (b *BytesRequest) Await(ctx context.Context) ([]byte, error) {
+	result, err := b.Request.await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bytesFromResult(result)
+}
+
+func bytesFromResult(result safejs.Value) ([]byte, error) {
+	if result.IsUndefined() {
+		return nil, nil
+	}
+	return BytesFromJS(safejs.Unsafe(result))
+}
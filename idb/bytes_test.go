@@ -0,0 +1,109 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestBytesFromJS(t *testing.T) {
+	t.Parallel()
+	buffer := js.Global().Get("Uint8Array").New(js.ValueOf([]interface{}{1, 2, 3, 4})).Get("buffer")
+	for _, tc := range []struct {
+		name      string
+		value     js.Value
+		expect    []byte
+		expectErr error
+	}{
+		{name: "array buffer", value: buffer, expect: []byte{1, 2, 3, 4}},
+		{name: "uint8 array", value: js.Global().Get("Uint8Array").New(buffer, 1, 2), expect: []byte{2, 3}},
+		{name: "data view", value: js.Global().Get("DataView").New(buffer, 2), expect: []byte{3, 4}},
+		{name: "uint16 array", value: js.Global().Get("Uint16Array").New(buffer, 2, 1), expect: []byte{3, 4}},
+		{name: "empty", value: js.Global().Get("Uint8Array").New(0), expect: []byte{}},
+		{name: "not bytes", value: js.ValueOf("hello"), expectErr: errNotBytes},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := BytesFromJS(tc.value)
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expect, b)
+		})
+	}
+}
+
+func TestBytesToJS(t *testing.T) {
+	t.Parallel()
+	array, err := BytesToJS([]byte{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, true, array.InstanceOf(js.Global().Get("Uint8Array")))
+	b, err := BytesFromJS(array)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, b)
+}
+
+func TestObjectStorePutGetBytes(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+
+	// writing the same size twice reuses a pooled array, which must not change the first value
+	_, err = store.PutBytes(js.ValueOf("a"), []byte("first"))
+	assert.NoError(t, err)
+	_, err = store.AddBytes(js.ValueOf("b"), []byte("again"))
+	assert.NoError(t, err)
+
+	for key, expect := range map[string][]byte{
+		"a":       []byte("first"),
+		"b":       []byte("again"),
+		"missing": nil,
+	} {
+		req, err := store.GetBytes(js.ValueOf(key))
+		assert.NoError(t, err)
+		b, err := req.Await(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, expect, b)
+	}
+}
+
+func TestCursorBytes(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutBytes(js.ValueOf("a"), []byte("before"))
+	assert.NoError(t, err)
+
+	cursorReq, err := store.OpenCursor(CursorNext)
+	assert.NoError(t, err)
+	err = cursorReq.Iter(context.Background(), func(cursor *CursorWithValue) error {
+		b, err := cursor.ValueBytes()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("before"), b)
+		_, err = cursor.UpdateBytes([]byte("after"))
+		return err
+	})
+	assert.NoError(t, err)
+
+	req, err := store.GetBytes(js.ValueOf("a"))
+	assert.NoError(t, err)
+	b, err := req.Await(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("after"), b)
+}
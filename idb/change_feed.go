@@ -0,0 +1,491 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
+	"github.com/hack-pad/safejs"
+)
+
+const changeFeedChannelPrefix = "go-indexeddb-changes:"
+
+var (
+	jsBroadcastChannel safejs.Value
+	// processID identifies the change feed messages published by this process
+	processID string
+
+	errBroadcastChannelUnsupported = errors.New("BroadcastChannel is not supported")
+	errTransactionAborted          = errors.New("Transaction aborted")
+
+	localChanges = newChangeListeners()
+)
+
+func init() {
+	var err error
+	jsBroadcastChannel, err = safejs.Global().Get("BroadcastChannel")
+	if err != nil {
+		panic(err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	processID = hex.EncodeToString(id)
+}
+
+// ChangeEvent describes the changes a committed readwrite transaction made to one object store
+type ChangeEvent struct {
+	// StoreName is the name of the changed object store
+	StoreName string `json:"store"`
+	// Keys are the sorted primary keys of the added, updated, and deleted records. Empty if AllKeys is true.
+	Keys []idbkey.Key `json:"keys,omitempty"`
+	// AllKeys is true if any record in the object store may have changed, like after ObjectStore.Clear()
+	AllKeys bool `json:"allKeys,omitempty"`
+}
+
+// changeMessage is a message published to a change feed's BroadcastChannel
+type changeMessage struct {
+	// Source is the processID of the publisher
+	Source string        `json:"src"`
+	Events []ChangeEvent `json:"events"`
+}
+
+// changeFeed publishes changes to a BroadcastChannel named after the database
+type changeFeed struct {
+	jsChannel safejs.Value
+}
+
+func newJSBroadcastChannel(dbName string) (safejs.Value, error) {
+	if jsBroadcastChannel.IsUndefined() {
+		return safejs.Value{}, errBroadcastChannelUnsupported
+	}
+	return jsBroadcastChannel.New(changeFeedChannelPrefix + dbName)
+}
+
+// EnableChangeFeed publishes the changes made by this connection's readwrite transactions after each one completes.
+// Changes are received with Watch, in this or any other tab or worker of the same origin.
+func (db *Database) EnableChangeFeed() error {
+	db.changeFeedMu.Lock()
+	defer db.changeFeedMu.Unlock()
+	if db.changeFeed != nil {
+		return nil
+	}
+	name, err := db.Name()
+	if err != nil {
+		return err
+	}
+	jsChannel, err := newJSBroadcastChannel(name)
+	if err != nil {
+		return err
+	}
+	db.changeFeed = &changeFeed{jsChannel: jsChannel}
+	return nil
+}
+
+func (db *Database) getChangeFeed() *changeFeed {
+	db.changeFeedMu.Lock()
+	defer db.changeFeedMu.Unlock()
+	return db.changeFeed
+}
+
+func (db *Database) closeChangeFeed() error {
+	db.changeFeedMu.Lock()
+	defer db.changeFeedMu.Unlock()
+	if db.changeFeed == nil {
+		return nil
+	}
+	_, err := db.changeFeed.jsChannel.Call("close")
+	db.changeFeed = nil
+	return err
+}
+
+func (f *changeFeed) publish(events []ChangeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	message, err := json.Marshal(changeMessage{Source: processID, Events: events})
+	if err != nil {
+		return err
+	}
+	_, err = f.jsChannel.Call("postMessage", string(message))
+	return err
+}
+
+// Watch returns a channel of changes to the given object stores, or every object store if none are given.
+// Changes are received from every connection to this database with its change feed enabled, including connections in other tabs and workers. See EnableChangeFeed.
+//
+// The channel is closed when ctx is canceled.
+func (db *Database) Watch(ctx context.Context, storeNames ...string) (<-chan ChangeEvent, error) {
+	return db.watch(ctx, false, storeNames)
+}
+
+// watch is the same as Watch, but skips changes published by this process if skipLocal is true
+func (db *Database) watch(ctx context.Context, skipLocal bool, storeNames []string) (<-chan ChangeEvent, error) {
+	name, err := db.Name()
+	if err != nil {
+		return nil, err
+	}
+	jsChannel, err := newJSBroadcastChannel(name)
+	if err != nil {
+		return nil, err
+	}
+
+	watchStores := make(map[string]bool, len(storeNames))
+	for _, storeName := range storeNames {
+		watchStores[storeName] = true
+	}
+	var (
+		queueMu sync.Mutex
+		queue   []ChangeEvent
+		notify  = make(chan struct{}, 1)
+	)
+	onMessage, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		message, err := parseChangeMessage(args)
+		if err != nil {
+			log.Println("Failed parsing change event:", err)
+			return nil
+		}
+		if skipLocal && message.Source == processID {
+			return nil
+		}
+		queueMu.Lock()
+		for _, event := range message.Events {
+			if len(watchStores) == 0 || watchStores[event.StoreName] {
+				queue = append(queue, event)
+			}
+		}
+		queueMu.Unlock()
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = jsChannel.Call(addEventListener, db.callStrings.Value("message"), onMessage)
+	if err != nil {
+		onMessage.Release()
+		return nil, tryAsDOMException(err)
+	}
+
+	events := make(chan ChangeEvent)
+	go func() {
+		defer close(events)
+		defer func() {
+			// clean up on best-effort basis
+			_, _ = jsChannel.Call(removeEventListener, db.callStrings.Value("message"), onMessage)
+			_, _ = jsChannel.Call("close")
+			onMessage.Release()
+		}()
+		for {
+			queueMu.Lock()
+			pending := queue
+			queue = nil
+			queueMu.Unlock()
+			for _, event := range pending {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func parseChangeMessage(args []safejs.Value) (changeMessage, error) {
+	if len(args) == 0 {
+		return changeMessage{}, nil
+	}
+	data, err := args[0].Get("data")
+	if err != nil {
+		return changeMessage{}, err
+	}
+	messageStr, err := data.String()
+	if err != nil {
+		return changeMessage{}, err
+	}
+	var message changeMessage
+	err = json.Unmarshal([]byte(messageStr), &message)
+	return message, err
+}
+
+// changeListeners receive the changes made by transactions in this process, keyed by database name
+type changeListeners struct {
+	mu        sync.Mutex
+	nextID    int
+	listeners map[string]map[int]func([]ChangeEvent)
+}
+
+func newChangeListeners() *changeListeners {
+	return &changeListeners{listeners: make(map[string]map[int]func([]ChangeEvent))}
+}
+
+// add registers listener for changes to the named database. Returns a func to remove the listener.
+func (c *changeListeners) add(dbName string, listener func([]ChangeEvent)) (remove func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextID
+	c.nextID++
+	if c.listeners[dbName] == nil {
+		c.listeners[dbName] = make(map[int]func([]ChangeEvent))
+	}
+	c.listeners[dbName][id] = listener
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.listeners[dbName], id)
+		if len(c.listeners[dbName]) == 0 {
+			delete(c.listeners, dbName)
+		}
+	}
+}
+
+// has returns true if there are listeners for the named database
+func (c *changeListeners) has(dbName string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.listeners[dbName]) > 0
+}
+
+func (c *changeListeners) notify(dbName string, events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	c.mu.Lock()
+	listeners := make([]func([]ChangeEvent), 0, len(c.listeners[dbName]))
+	for _, listener := range c.listeners[dbName] {
+		listeners = append(listeners, listener)
+	}
+	c.mu.Unlock()
+	for _, listener := range listeners {
+		listener(events)
+	}
+}
+
+// changeSet tracks the changes made by a readwrite transaction
+type changeSet struct {
+	mu         sync.Mutex
+	storeNames []string // in order of first change
+	stores     map[string]*storeChangeSet
+}
+
+type storeChangeSet struct {
+	keys     []idbkey.Key
+	requests []*Request // requests which resolve to changed primary keys, like Put
+	allKeys  bool
+}
+
+func newChangeSet() *changeSet {
+	return &changeSet{stores: make(map[string]*storeChangeSet, 1)}
+}
+
+func (c *changeSet) store(storeName string) *storeChangeSet {
+	store, ok := c.stores[storeName]
+	if !ok {
+		store = &storeChangeSet{}
+		c.stores[storeName] = store
+		c.storeNames = append(c.storeNames, storeName)
+	}
+	return store
+}
+
+func (c *changeSet) addKey(storeName string, key safejs.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	store := c.store(storeName)
+	parsedKey, err := parseJSKey(key)
+	if err != nil {
+		// not a single key, like an IDBKeyRange
+		store.allKeys = true
+		return
+	}
+	store.keys = append(store.keys, parsedKey)
+}
+
+func (c *changeSet) addRequest(storeName string, req *Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	store := c.store(storeName)
+	store.requests = append(store.requests, req)
+}
+
+func (c *changeSet) addAllKeys(storeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(storeName).allKeys = true
+}
+
+// events returns the changes for each object store. Must only be called after the transaction completes.
+func (c *changeSet) events() []ChangeEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	events := make([]ChangeEvent, 0, len(c.storeNames))
+	for _, storeName := range c.storeNames {
+		store := c.stores[storeName]
+		if store.allKeys {
+			events = append(events, ChangeEvent{StoreName: storeName, AllKeys: true})
+			continue
+		}
+		keys := store.keys
+		for _, req := range store.requests {
+			result, err := req.result()
+			if err != nil {
+				continue
+			}
+			key, err := parseJSKey(result)
+			if err != nil {
+				continue // failed requests have no key and made no change
+			}
+			keys = append(keys, key)
+		}
+		keys = sortUniqueKeys(keys)
+		if len(keys) > 0 {
+			events = append(events, ChangeEvent{StoreName: storeName, Keys: keys})
+		}
+	}
+	return events
+}
+
+func sortUniqueKeys(keys []idbkey.Key) []idbkey.Key {
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].Compare(keys[b]) < 0
+	})
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || !key.Equal(keys[i-1]) {
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// publishChangesOnComplete tracks the changes made in this transaction. If the transaction completes successfully, publishes them to feed (if not nil) and this process's listeners.
+func (t *Transaction) publishChangesOnComplete(feed *changeFeed, dbName string) error {
+	t.changes = newChangeSet()
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	if err := t.addCancelingEventListener(ctx, cancel, "complete", result, func(safejs.Value) error {
+		return nil
+	}); err != nil {
+		cancel()
+		return err
+	}
+	if err := t.addCancelingEventListener(ctx, cancel, "abort", result, func(safejs.Value) error {
+		return errTransactionAborted
+	}); err != nil {
+		cancel()
+		return err
+	}
+	go func() {
+		if err := <-result; err != nil {
+			return
+		}
+		events := t.changes.events()
+		localChanges.notify(dbName, events)
+		if feed == nil {
+			return
+		}
+		if err := feed.publish(events); err != nil {
+			log.Println("Failed publishing transaction changes:", err)
+		}
+	}()
+	return nil
+}
+
+// trackKey records a change to key in the given object store, if this transaction is tracking changes
+func (t *Transaction) trackKey(jsObjectStore, key safejs.Value) {
+	if t == nil || t.changes == nil {
+		return
+	}
+	if storeName, err := jsObjectStoreName(jsObjectStore); err == nil {
+		t.changes.addKey(storeName, key)
+	}
+}
+
+// trackRequest records a change to the key req resolves to in the given object store, if this transaction is tracking changes
+func (t *Transaction) trackRequest(jsObjectStore safejs.Value, req *Request) {
+	if t == nil || t.changes == nil {
+		return
+	}
+	if storeName, err := jsObjectStoreName(jsObjectStore); err == nil {
+		t.changes.addRequest(storeName, req)
+	}
+}
+
+// trackAllKeys records a change to any key in the given object store, if this transaction is tracking changes
+func (t *Transaction) trackAllKeys(jsObjectStore safejs.Value) {
+	if t == nil || t.changes == nil {
+		return
+	}
+	if storeName, err := jsObjectStoreName(jsObjectStore); err == nil {
+		t.changes.addAllKeys(storeName)
+	}
+}
+
+func jsObjectStoreName(jsObjectStore safejs.Value) (string, error) {
+	name, err := jsObjectStore.Get("name")
+	if err != nil {
+		return "", err
+	}
+	return name.String()
+}
+
+// jsCursorObjectStore returns the object store a cursor iterates, either directly or through an index
+func jsCursorObjectStore(jsCursor safejs.Value) (safejs.Value, error) {
+	source, err := jsCursor.Get("source")
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	isIndex, err := source.InstanceOf(jsIDBIndex)
+	if err != nil || !isIndex {
+		return source, err
+	}
+	return source.Get("objectStore")
+}
+
+// trackCursorKey records a change to the cursor's current primary key, if this transaction is tracking changes
+func (t *Transaction) trackCursorKey(jsCursor safejs.Value) {
+	if t == nil || t.changes == nil {
+		return
+	}
+	jsObjectStore, err := jsCursorObjectStore(jsCursor)
+	if err != nil {
+		return
+	}
+	primaryKey, err := jsCursor.Get("primaryKey")
+	if err != nil {
+		return
+	}
+	t.trackKey(jsObjectStore, primaryKey)
+}
+
+// trackCursorRequest records a change to the key req resolves to in the cursor's object store, if this transaction is tracking changes
+func (t *Transaction) trackCursorRequest(jsCursor safejs.Value, req *Request) {
+	if t == nil || t.changes == nil {
+		return
+	}
+	jsObjectStore, err := jsCursorObjectStore(jsCursor)
+	if err != nil {
+		return
+	}
+	t.trackRequest(jsObjectStore, req)
+}
@@ -0,0 +1,189 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func testChangeFeedDB(tb testing.TB) *Database {
+	tb.Helper()
+	db := testDB(tb, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(tb, err)
+		_, err = db.CreateObjectStore("otherstore", ObjectStoreOptions{
+			KeyPath:       js.ValueOf("id"),
+			AutoIncrement: true,
+		})
+		assert.NoError(tb, err)
+	})
+	return db
+}
+
+func receiveChange(tb testing.TB, events <-chan ChangeEvent) ChangeEvent {
+	tb.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		tb.Fatal("Timed out waiting for change event")
+		return ChangeEvent{}
+	}
+}
+
+func TestDatabaseWatch(t *testing.T) {
+	t.Parallel()
+	db := testChangeFeedDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, db.EnableChangeFeed())
+	events, err := db.Watch(ctx)
+	assert.NoError(t, err)
+
+	txn, err := db.Transaction(TransactionReadWrite, "mystore", "otherstore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKey(js.ValueOf("b"), js.ValueOf("value b"))
+	assert.NoError(t, err)
+	_, err = store.AddKey(js.ValueOf("a"), js.ValueOf("value a"))
+	assert.NoError(t, err)
+	_, err = store.Delete(js.ValueOf("b"))
+	assert.NoError(t, err)
+	otherStore, err := txn.ObjectStore("otherstore")
+	assert.NoError(t, err)
+	_, err = otherStore.Add(js.ValueOf(map[string]interface{}{}))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.Equal(t, ChangeEvent{
+		StoreName: "mystore",
+		Keys:      []idbkey.Key{idbkey.String("a"), idbkey.String("b")},
+	}, receiveChange(t, events))
+	assert.Equal(t, ChangeEvent{
+		StoreName: "otherstore",
+		Keys:      []idbkey.Key{idbkey.Number(1)},
+	}, receiveChange(t, events))
+}
+
+func TestDatabaseWatchStoreNames(t *testing.T) {
+	t.Parallel()
+	db := testChangeFeedDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, db.EnableChangeFeed())
+	events, err := db.Watch(ctx, "mystore")
+	assert.NoError(t, err)
+
+	// changes to otherstore are skipped
+	txn, err := db.Transaction(TransactionReadWrite, "otherstore")
+	assert.NoError(t, err)
+	otherStore, err := txn.ObjectStore("otherstore")
+	assert.NoError(t, err)
+	_, err = otherStore.Put(js.ValueOf(map[string]interface{}{}))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.Clear()
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.Equal(t, ChangeEvent{StoreName: "mystore", AllKeys: true}, receiveChange(t, events))
+}
+
+func TestDatabaseWatchCursor(t *testing.T) {
+	t.Parallel()
+	db := testChangeFeedDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// writes before enabling the change feed aren't published
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for _, key := range []string{"a", "b"} {
+		_, err := store.PutKey(js.ValueOf(key), js.ValueOf(key))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.NoError(t, db.EnableChangeFeed())
+	events, err := db.Watch(ctx, "mystore")
+	assert.NoError(t, err)
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	cursorReq, err := store.OpenCursor(CursorNext)
+	assert.NoError(t, err)
+	assert.NoError(t, cursorReq.Iter(ctx, func(cursor *CursorWithValue) error {
+		key, err := cursor.Key()
+		assert.NoError(t, err)
+		if key.String() == "a" {
+			_, err = cursor.Update(js.ValueOf("updated"))
+		} else {
+			_, err = cursor.Delete()
+		}
+		return err
+	}))
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.Equal(t, ChangeEvent{
+		StoreName: "mystore",
+		Keys:      []idbkey.Key{idbkey.String("a"), idbkey.String("b")},
+	}, receiveChange(t, events))
+}
+
+func TestDatabaseWatchAbort(t *testing.T) {
+	t.Parallel()
+	db := testChangeFeedDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, db.EnableChangeFeed())
+	events, err := db.Watch(ctx, "mystore")
+	assert.NoError(t, err)
+
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKey(js.ValueOf("aborted"), js.ValueOf("value"))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Abort())
+
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.PutKey(js.ValueOf("committed"), js.ValueOf("value"))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	assert.Equal(t, ChangeEvent{
+		StoreName: "mystore",
+		Keys:      []idbkey.Key{idbkey.String("committed")},
+	}, receiveChange(t, events))
+}
+
+func TestDatabaseWatchClosesOnCancel(t *testing.T) {
+	t.Parallel()
+	db := testChangeFeedDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := db.Watch(ctx)
+	assert.NoError(t, err)
+	cancel()
+	_, open := <-events
+	assert.Equal(t, false, open)
+}
@@ -0,0 +1,36 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+// NewCompoundKey creates an array key from the given components, in the same order as a compound key path.
+// Components can be numbers, strings, time.Time, []byte, []interface{} arrays of components, or js.Value keys.
+func NewCompoundKey(components ...interface{}) (js.Value, error) {
+	k, err := parseKey(components)
+	if err != nil {
+		return js.Value{}, err
+	}
+	value, err := jsKey(k)
+	return safejs.Unsafe(value), err
+}
+
+// CreateCompoundIndex is the same as CreateIndex, but indexes records by an array of key paths. Records are only indexed when every key path resolves to a valid key.
+func (o *ObjectStore) CreateCompoundIndex(name string, keyPaths []string, options IndexOptions) (*Index, error) {
+	keyPath, err := safejs.ValueOf(sliceFromStrings(keyPaths))
+	if err != nil {
+		return nil, err
+	}
+	return o.CreateIndex(name, safejs.Unsafe(keyPath), options)
+}
+
+// NewKeyRangeCompoundPrefix creates a new key range containing every array key whose leading components are equal to prefix.
+// Components use the same types as NewCompoundKey. An empty prefix matches all array keys.
+func NewKeyRangeCompoundPrefix(prefix ...interface{}) (*KeyRange, error) {
+	return NewKeyRangePrefix(prefix)
+}
@@ -0,0 +1,97 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestNewCompoundKey(t *testing.T) {
+	t.Parallel()
+	compoundKey, err := NewCompoundKey("user", 1)
+	assert.NoError(t, err)
+	compare, err := Global().CompareKeys(compoundKey, js.ValueOf([]interface{}{"user", 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, compare)
+
+	_, err = NewCompoundKey("user", true)
+	assert.ErrorIs(t, err, NewDOMException("DataError"))
+}
+
+func TestObjectStoreCreateCompoundIndex(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateCompoundIndex("myindex", []string{"user", "time"}, IndexOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+
+	keyPath, err := index.KeyPath()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keyPath.Length())
+	assert.Equal(t, "user", keyPath.Index(0).String())
+	assert.Equal(t, "time", keyPath.Index(1).String())
+
+	_, err = store.AddKey(js.ValueOf("a"), js.ValueOf(map[string]interface{}{"user": "bob", "time": 1}))
+	assert.NoError(t, err)
+	indexKey, err := NewCompoundKey("bob", 1)
+	assert.NoError(t, err)
+	req, err := index.GetKey(indexKey)
+	assert.NoError(t, err)
+	result, err := req.Await(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, js.ValueOf("a"), result)
+}
+
+func TestNewKeyRangeCompoundPrefix(t *testing.T) {
+	t.Parallel()
+	keyRange, err := NewKeyRangeCompoundPrefix("b", 1)
+	assert.NoError(t, err)
+	for _, tc := range []struct {
+		name           string
+		key            []interface{}
+		expectIncludes bool
+	}{
+		{name: "prefix", key: []interface{}{"b", 1}, expectIncludes: true},
+		{name: "number suffix", key: []interface{}{"b", 1, 0}, expectIncludes: true},
+		{name: "array suffix", key: []interface{}{"b", 1, []interface{}{"z"}}, expectIncludes: true},
+		{name: "shorter key", key: []interface{}{"b"}, expectIncludes: false},
+		{name: "greater last component", key: []interface{}{"b", 1.5}, expectIncludes: false},
+		{name: "smaller last component", key: []interface{}{"b", 0, 1}, expectIncludes: false},
+		{name: "greater first component", key: []interface{}{"c", 1}, expectIncludes: false},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			k, err := NewCompoundKey(tc.key...)
+			assert.NoError(t, err)
+			includes, err := keyRange.Includes(k)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectIncludes, includes)
+		})
+	}
+
+	t.Run("empty prefix", func(t *testing.T) {
+		t.Parallel()
+		keyRange, err := NewKeyRangeCompoundPrefix()
+		assert.NoError(t, err)
+		includes, err := keyRange.Includes(js.ValueOf([]interface{}{}))
+		assert.NoError(t, err)
+		assert.Equal(t, true, includes)
+		includes, err = keyRange.Includes(js.ValueOf("a"))
+		assert.NoError(t, err)
+		assert.Equal(t, false, includes)
+	})
+}
@@ -0,0 +1,288 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package compress stores compressed values in IndexedDB.
+//
+// Values are encoded as JSON, then compressed with the browser's CompressionStream, or with a pure Go implementation if CompressionStream is unavailable. Compressed records start with a short header, so compressed and uncompressed records can coexist in the same store. Decode returns records without the header unchanged, which makes it safe to enable compression on an existing store.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/promise"
+	"github.com/hack-pad/safejs"
+)
+
+const headerVersion = 1
+
+var (
+	// header starts every compressed record, followed by the header version and Format
+	header = []byte{0x89, 'I', 'D', 'Z'}
+
+	jsArray               safejs.Value
+	jsBlob                safejs.Value
+	jsCompressionStream   safejs.Value
+	jsDecompressionStream safejs.Value
+	jsJSON                safejs.Value
+	jsResponse            safejs.Value
+	jsUint8Array          safejs.Value
+
+	errNotJSON                = errors.New("compress: value has no JSON representation")
+	errUnsupportedVersion     = errors.New("compress: unsupported header version")
+	errCompressionUnsupported = errors.New("compress: CompressionStream is not supported")
+)
+
+func init() {
+	for _, global := range []struct {
+		name  string
+		value *safejs.Value
+	}{
+		{"Array", &jsArray},
+		{"Blob", &jsBlob},
+		{"CompressionStream", &jsCompressionStream},
+		{"DecompressionStream", &jsDecompressionStream},
+		{"JSON", &jsJSON},
+		{"Response", &jsResponse},
+		{"Uint8Array", &jsUint8Array},
+	} {
+		value, err := safejs.Global().Get(global.name)
+		if err != nil {
+			panic(err)
+		}
+		*global.value = value
+	}
+}
+
+// Format is a compression format
+type Format byte
+
+const (
+	// Gzip compresses with the gzip format. This is the default.
+	Gzip Format = iota + 1
+	// Deflate compresses with the zlib format, called "deflate" by CompressionStream
+	Deflate
+)
+
+func (f Format) String() string {
+	switch f {
+	case Gzip:
+		return "gzip"
+	case Deflate:
+		return "deflate"
+	default:
+		return fmt.Sprintf("Format(%d)", byte(f))
+	}
+}
+
+func (f Format) valid() bool {
+	return f == Gzip || f == Deflate
+}
+
+// Options contains all available options for encoding values
+type Options struct {
+	// Format is the compression format. Defaults to Gzip.
+	Format Format
+	// MinSize is the smallest JSON-encoded value to compress, in bytes. Smaller values are stored as-is, since compression rarely pays off for them.
+	MinSize int
+}
+
+// Encode returns value compressed with a header, or value itself if its JSON encoding is smaller than options.MinSize.
+// value must be JSON-compatible. Dates, Blobs, and other values without a JSON representation are not preserved.
+//
+// CompressionStream is asynchronous, so Encode values before starting a transaction. Use EncodeSync inside a transaction, like in a cursor's iteration.
+func Encode(ctx context.Context, value js.Value, options Options) (js.Value, error) {
+	return encode(value, options, func(data []byte, format Format) ([]byte, error) {
+		if jsCompressionStream.IsUndefined() {
+			return compressGo(data, format)
+		}
+		return compressJS(ctx, data, format)
+	})
+}
+
+// EncodeSync is the same as Encode, but always uses the pure Go implementation. It's slower than Encode, but safe to use inside a transaction.
+func EncodeSync(value js.Value, options Options) (js.Value, error) {
+	return encode(value, options, compressGo)
+}
+
+func encode(value js.Value, options Options, compress func([]byte, Format) ([]byte, error)) (js.Value, error) {
+	if options.Format == 0 {
+		options.Format = Gzip
+	}
+	if !options.Format.valid() {
+		return js.Value{}, fmt.Errorf("compress: invalid format: %s", options.Format)
+	}
+	jsonValue, err := jsJSON.Call("stringify", value)
+	if err != nil {
+		return js.Value{}, err
+	}
+	if jsonValue.IsUndefined() {
+		return js.Value{}, errNotJSON
+	}
+	jsonString, err := jsonValue.String()
+	if err != nil {
+		return js.Value{}, err
+	}
+	if len(jsonString) < options.MinSize {
+		return value, nil
+	}
+	compressed, err := compress([]byte(jsonString), options.Format)
+	if err != nil {
+		return js.Value{}, err
+	}
+	record := make([]byte, 0, len(header)+2+len(compressed))
+	record = append(record, header...)
+	record = append(record, headerVersion, byte(options.Format))
+	record = append(record, compressed...)
+	return idb.BytesToJS(record)
+}
+
+// Decode returns the decompressed value of a record returned by Encode. Records without a header are returned unchanged.
+//
+// DecompressionStream is asynchronous, so Decode values after their transaction completes. Use DecodeSync inside a transaction, like in a cursor's iteration.
+func Decode(ctx context.Context, value js.Value) (js.Value, error) {
+	return decode(value, func(compressed []byte, format Format) ([]byte, error) {
+		if jsDecompressionStream.IsUndefined() {
+			return decompressGo(compressed, format)
+		}
+		return decompressJS(ctx, compressed, format)
+	})
+}
+
+// DecodeSync is the same as Decode, but always uses the pure Go implementation. It's slower than Decode, but safe to use inside a transaction.
+func DecodeSync(value js.Value) (js.Value, error) {
+	return decode(value, decompressGo)
+}
+
+// IsEncoded returns true if value is a record with a compression header
+func IsEncoded(value js.Value) bool {
+	_, _, ok := parseHeader(value)
+	return ok
+}
+
+func decode(value js.Value, decompress func([]byte, Format) ([]byte, error)) (js.Value, error) {
+	record, format, ok := parseHeader(value)
+	if !ok {
+		return value, nil
+	}
+	version := record[len(header)]
+	if version != headerVersion {
+		return js.Value{}, fmt.Errorf("%w: %d", errUnsupportedVersion, version)
+	}
+	if !format.valid() {
+		return js.Value{}, fmt.Errorf("compress: invalid format: %s", format)
+	}
+	jsonBytes, err := decompress(record[len(header)+2:], format)
+	if err != nil {
+		return js.Value{}, err
+	}
+	decoded, err := jsJSON.Call("parse", string(jsonBytes))
+	return safejs.Unsafe(decoded), err
+}
+
+// parseHeader returns value's bytes and compression format, if value is a Uint8Array starting with a header
+func parseHeader(value js.Value) ([]byte, Format, bool) {
+	isArray, err := safejs.Safe(value).InstanceOf(jsUint8Array)
+	if err != nil || !isArray {
+		return nil, 0, false
+	}
+	record, err := idb.BytesFromJS(value)
+	if err != nil || len(record) < len(header)+2 || !bytes.HasPrefix(record, header) {
+		return nil, 0, false
+	}
+	return record, Format(record[len(header)+1]), true
+}
+
+func compressGo(data []byte, format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch format {
+	case Deflate:
+		writer = zlib.NewWriter(&buf)
+	default:
+		writer = gzip.NewWriter(&buf)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	err := writer.Close()
+	return buf.Bytes(), err
+}
+
+func decompressGo(data []byte, format Format) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch format {
+	case Deflate:
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return decompressed, reader.Close()
+}
+
+func compressJS(ctx context.Context, data []byte, format Format) ([]byte, error) {
+	return transformJS(ctx, jsCompressionStream, data, format)
+}
+
+func decompressJS(ctx context.Context, data []byte, format Format) ([]byte, error) {
+	return transformJS(ctx, jsDecompressionStream, data, format)
+}
+
+// transformJS pipes data through a new CompressionStream or DecompressionStream, then returns the result
+func transformJS(ctx context.Context, streamConstructor safejs.Value, data []byte, format Format) ([]byte, error) {
+	if streamConstructor.IsUndefined() {
+		return nil, errCompressionUnsupported
+	}
+	jsData, err := idb.BytesToJS(data)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := jsArray.New(jsData)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := jsBlob.New(parts)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := blob.Call("stream")
+	if err != nil {
+		return nil, err
+	}
+	transform, err := streamConstructor.New(format.String())
+	if err != nil {
+		return nil, err
+	}
+	transformed, err := stream.Call("pipeThrough", transform)
+	if err != nil {
+		return nil, err
+	}
+	response, err := jsResponse.New(transformed)
+	if err != nil {
+		return nil, err
+	}
+	arrayBufferPromise, err := response.Call("arrayBuffer")
+	if err != nil {
+		return nil, err
+	}
+	arrayBuffer, err := promise.Await(ctx, arrayBufferPromise)
+	if err != nil {
+		return nil, err
+	}
+	return idb.BytesFromJS(safejs.Unsafe(arrayBuffer))
+}
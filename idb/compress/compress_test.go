@@ -0,0 +1,110 @@
+//go:build js && wasm
+// +build js,wasm
+
+package compress
+
+import (
+	"context"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func testDocument() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"title": "hello",
+		"body":  strings.Repeat("lorem ipsum ", 100),
+		"tags":  []interface{}{"a", "b"},
+		"count": 3,
+	})
+}
+
+func jsonString(value js.Value) string {
+	return js.Global().Get("JSON").Call("stringify", value).String()
+}
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+	type codec struct {
+		encode func(js.Value, Options) (js.Value, error)
+		decode func(js.Value) (js.Value, error)
+	}
+	asyncCodec := codec{
+		encode: func(value js.Value, options Options) (js.Value, error) {
+			return Encode(context.Background(), value, options)
+		},
+		decode: func(value js.Value) (js.Value, error) {
+			return Decode(context.Background(), value)
+		},
+	}
+	syncCodec := codec{encode: EncodeSync, decode: DecodeSync}
+	for _, tc := range []struct {
+		name   string
+		format Format
+		encode codec
+		decode codec
+	}{
+		{name: "gzip", format: Gzip, encode: asyncCodec, decode: asyncCodec},
+		{name: "deflate", format: Deflate, encode: asyncCodec, decode: asyncCodec},
+		{name: "default format", encode: asyncCodec, decode: asyncCodec},
+		{name: "gzip sync", format: Gzip, encode: syncCodec, decode: syncCodec},
+		{name: "deflate sync", format: Deflate, encode: syncCodec, decode: syncCodec},
+		{name: "gzip async to sync", format: Gzip, encode: asyncCodec, decode: syncCodec},
+		{name: "deflate sync to async", format: Deflate, encode: syncCodec, decode: asyncCodec},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			document := testDocument()
+			encoded, err := tc.encode.encode(document, Options{Format: tc.format})
+			assert.NoError(t, err)
+			assert.Equal(t, true, IsEncoded(encoded))
+			assert.Equal(t, true, encoded.Length() < len(jsonString(document)))
+
+			decoded, err := tc.decode.decode(encoded)
+			assert.NoError(t, err)
+			assert.Equal(t, jsonString(document), jsonString(decoded))
+		})
+	}
+}
+
+func TestEncodeMinSize(t *testing.T) {
+	t.Parallel()
+	value := js.ValueOf(map[string]interface{}{"small": true})
+	encoded, err := Encode(context.Background(), value, Options{MinSize: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, false, IsEncoded(encoded))
+	assert.Equal(t, true, encoded.Equal(value))
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := Encode(context.Background(), js.Undefined(), Options{})
+	assert.ErrorIs(t, err, errNotJSON)
+	_, err = Encode(context.Background(), testDocument(), Options{Format: 99})
+	assert.Error(t, err)
+}
+
+func TestDecodeUncompressed(t *testing.T) {
+	t.Parallel()
+	for _, value := range []js.Value{
+		js.ValueOf("plain string"),
+		js.ValueOf(map[string]interface{}{"a": 1}),
+		js.Global().Get("Uint8Array").New(js.ValueOf([]interface{}{1, 2, 3})),
+	} {
+		decoded, err := Decode(context.Background(), value)
+		assert.NoError(t, err)
+		assert.Equal(t, true, decoded.Equal(value))
+	}
+}
+
+func TestDecodeUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+	encoded, err := EncodeSync(testDocument(), Options{})
+	assert.NoError(t, err)
+	encoded.SetIndex(len(header), headerVersion+1)
+	_, err = Decode(context.Background(), encoded)
+	assert.ErrorIs(t, err, errUnsupportedVersion)
+}
@@ -0,0 +1,175 @@
+//go:build js && wasm
+// +build js,wasm
+
+package compress
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+)
+
+// Store compresses values in an existing object store. Values are encoded before each write transaction starts, and decoded after each read transaction completes.
+type Store struct {
+	db        *idb.Database
+	storeName string
+	options   Options
+}
+
+// Wrap returns a Store which compresses values in db's object store named storeName
+func Wrap(db *idb.Database, storeName string, options Options) *Store {
+	return &Store{db: db, storeName: storeName, options: options}
+}
+
+// Get returns the decoded value for key, or undefined if key does not exist
+func (s *Store) Get(ctx context.Context, key js.Value) (js.Value, error) {
+	var value js.Value
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := store.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = req.Await(ctx)
+		return err
+	})
+	if err != nil {
+		return js.Value{}, err
+	}
+	return Decode(ctx, value)
+}
+
+// Add encodes value, then adds it with the given key. key may be undefined for stores with a key generator. Returns the new record's key.
+// Stores with in-line keys are not supported, since encoded values hide their key paths.
+func (s *Store) Add(ctx context.Context, key, value js.Value) (js.Value, error) {
+	return s.write(ctx, key, value, func(store *idb.ObjectStore, encoded js.Value) (*idb.Request, error) {
+		req, err := store.AddKey(key, encoded)
+		if err != nil {
+			return nil, err
+		}
+		return req.Request, nil
+	})
+}
+
+// Put encodes value, then puts it with the given key. key may be undefined for stores with a key generator. Returns the record's key.
+// Stores with in-line keys are not supported, since encoded values hide their key paths.
+func (s *Store) Put(ctx context.Context, key, value js.Value) (js.Value, error) {
+	return s.write(ctx, key, value, func(store *idb.ObjectStore, encoded js.Value) (*idb.Request, error) {
+		return store.PutKey(key, encoded)
+	})
+}
+
+func (s *Store) write(ctx context.Context, key, value js.Value, writeFn func(*idb.ObjectStore, js.Value) (*idb.Request, error)) (js.Value, error) {
+	encoded, err := Encode(ctx, value, s.options)
+	if err != nil {
+		return js.Value{}, err
+	}
+	var resultKey js.Value
+	err = txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := writeFn(store, encoded)
+		if err != nil {
+			return err
+		}
+		resultKey, err = req.Await(ctx)
+		return err
+	})
+	return resultKey, err
+}
+
+// Delete deletes the record for key
+func (s *Store) Delete(ctx context.Context, key js.Value) error {
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		_, err := store.Delete(key)
+		return err
+	})
+}
+
+// Iter calls fn with the key and decoded value of every record in keyRange, in key order. A nil keyRange iterates all records.
+// Records are decoded with DecodeSync inside a single read transaction, so fn must not wait on other asynchronous work. Stops if fn returns an error, and returns that error.
+func (s *Store) Iter(ctx context.Context, keyRange *idb.KeyRange, fn func(key, value js.Value) error) error {
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		var req *idb.CursorWithValueRequest
+		var err error
+		if keyRange == nil {
+			req, err = store.OpenCursor(idb.CursorNext)
+		} else {
+			req, err = store.OpenCursorRange(keyRange, idb.CursorNext)
+		}
+		if err != nil {
+			return err
+		}
+		return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			decoded, err := DecodeSync(value)
+			if err != nil {
+				return err
+			}
+			return fn(key, decoded)
+		})
+	})
+}
+
+// Migrate compresses every record in the store which is not compressed yet, visiting batchSize records per transaction. A batchSize of 0 visits 100 records per transaction.
+// Useful after enabling compression on an existing store. Records are compressed with EncodeSync in place, so other connections can keep reading and writing while Migrate runs.
+func (s *Store) Migrate(ctx context.Context, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	var lastKey js.Value
+	for {
+		var visited int
+		err := txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+			var req *idb.CursorWithValueRequest
+			var err error
+			if lastKey.IsUndefined() {
+				req, err = store.OpenCursor(idb.CursorNext)
+			} else {
+				keyRange, rangeErr := idb.NewKeyRangeLowerBound(lastKey, true)
+				if rangeErr != nil {
+					return rangeErr
+				}
+				req, err = store.OpenCursorRange(keyRange, idb.CursorNext)
+			}
+			if err != nil {
+				return err
+			}
+			return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+				visited++
+				lastKey, err = cursor.Key()
+				if err != nil {
+					return err
+				}
+				value, err := cursor.Value()
+				if err != nil {
+					return err
+				}
+				if !IsEncoded(value) {
+					encoded, err := EncodeSync(value, s.options)
+					if err != nil {
+						return err
+					}
+					if IsEncoded(encoded) { // values smaller than MinSize stay as-is
+						if _, err := cursor.Update(encoded); err != nil {
+							return err
+						}
+					}
+				}
+				if visited == batchSize {
+					return idb.ErrCursorStopIter
+				}
+				return nil
+			})
+		})
+		if err != nil || visited < batchSize {
+			return err
+		}
+	}
+}
@@ -0,0 +1,117 @@
+//go:build js && wasm
+// +build js,wasm
+
+package compress
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testStore(tb testing.TB, options Options) *Store {
+	tb.Helper()
+	db := idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("mystore", idb.ObjectStoreOptions{})
+		return err
+	})
+	return Wrap(db, "mystore", options)
+}
+
+// getRaw returns the stored value for key without decoding it
+func getRaw(tb testing.TB, store *Store, key string) js.Value {
+	tb.Helper()
+	txn, err := store.db.Transaction(idb.TransactionReadOnly, store.storeName)
+	assert.NoError(tb, err)
+	objectStore, err := txn.ObjectStore(store.storeName)
+	assert.NoError(tb, err)
+	req, err := objectStore.Get(js.ValueOf(key))
+	assert.NoError(tb, err)
+	value, err := req.Await(context.Background())
+	assert.NoError(tb, err)
+	return value
+}
+
+func TestStorePutGet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{})
+	document := testDocument()
+	key, err := store.Put(ctx, js.ValueOf("doc"), document)
+	assert.NoError(t, err)
+	assert.Equal(t, js.ValueOf("doc"), key)
+	assert.Equal(t, true, IsEncoded(getRaw(t, store, "doc")))
+
+	value, err := store.Get(ctx, js.ValueOf("doc"))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonString(document), jsonString(value))
+
+	value, err = store.Get(ctx, js.ValueOf("missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.IsUndefined())
+
+	assert.NoError(t, store.Delete(ctx, js.ValueOf("doc")))
+	value, err = store.Get(ctx, js.ValueOf("doc"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.IsUndefined())
+}
+
+func TestStoreAdd(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{})
+	_, err := store.Add(ctx, js.ValueOf("doc"), testDocument())
+	assert.NoError(t, err)
+	_, err = store.Add(ctx, js.ValueOf("doc"), testDocument())
+	assert.ErrorIs(t, err, idb.NewDOMException("ConstraintError"))
+}
+
+func TestStoreIterMixed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{MinSize: 100})
+	_, err := store.Put(ctx, js.ValueOf("a"), testDocument())
+	assert.NoError(t, err)
+	_, err = store.Put(ctx, js.ValueOf("b"), js.ValueOf("small"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, IsEncoded(getRaw(t, store, "a")))
+	assert.Equal(t, js.ValueOf("small"), getRaw(t, store, "b"))
+
+	var keys []string
+	var values []string
+	err = store.Iter(ctx, nil, func(key, value js.Value) error {
+		keys = append(keys, key.String())
+		values = append(values, jsonString(value))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, []string{jsonString(testDocument()), `"small"`}, values)
+}
+
+func TestStoreMigrate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	uncompressed := testStore(t, Options{MinSize: 1 << 20})
+	for i := 0; i < 5; i++ {
+		_, err := uncompressed.Put(ctx, js.ValueOf(fmt.Sprint(i)), testDocument())
+		assert.NoError(t, err)
+	}
+	_, err := uncompressed.Put(ctx, js.ValueOf("small"), js.ValueOf("small"))
+	assert.NoError(t, err)
+
+	store := Wrap(uncompressed.db, uncompressed.storeName, Options{MinSize: 100})
+	assert.NoError(t, store.Migrate(ctx, 2))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, true, IsEncoded(getRaw(t, store, fmt.Sprint(i))))
+	}
+	assert.Equal(t, js.ValueOf("small"), getRaw(t, store, "small"))
+	value, err := store.Get(ctx, js.ValueOf("3"))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonString(testDocument()), jsonString(value))
+}
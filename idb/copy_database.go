@@ -0,0 +1,441 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+// DefaultCopyBatchSize is the default number of records copied in each transaction by Factory.CopyDatabase
+const DefaultCopyBatchSize = 1000
+
+var (
+	errCopySourceNotFound    = errors.New("Source database does not exist")
+	errCopyDestinationExists = errors.New("Destination database already exists with a different schema")
+)
+
+// CopyOptions contains all available options for copying a database
+type CopyOptions struct {
+	// BatchSize is the number of records copied in each transaction. Defaults to DefaultCopyBatchSize.
+	BatchSize int
+	// DeleteSource deletes the source database once every record is copied, which completes a rename
+	DeleteSource bool
+	// Progress is called after each batch of records is copied
+	Progress func(CopyProgress)
+}
+
+// CopyProgress describes how much of an object store has been copied
+type CopyProgress struct {
+	// ObjectStore is the name of the object store being copied
+	ObjectStore string
+	// Copied is the number of records copied to the destination so far, including any copied before resuming
+	Copied uint
+	// Total is the number of records in the source object store
+	Total uint
+}
+
+type copyStoreSchema struct {
+	name          string
+	keyPath       js.Value
+	autoIncrement bool
+	indexes       []copyIndexSchema
+}
+
+type copyIndexSchema struct {
+	name       string
+	keyPath    js.Value
+	unique     bool
+	multiEntry bool
+}
+
+// CopyDatabase copies every object store, index, and record from the src database into a new dst database with the same version.
+// Records are copied in key order in batched transactions, so an interrupted copy resumes after the last copied record when called again with the same arguments.
+//
+// Returns an error if src does not exist, or if dst already exists with a different version, object stores, key paths, key generators, or indexes.
+// Key generators are not copied directly, so an auto-increment store's next key in dst follows its largest copied key.
+func (f *Factory) CopyDatabase(ctx context.Context, src, dst string, options CopyOptions) error {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultCopyBatchSize
+	}
+	srcDB, err := f.openCopySource(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = srcDB.Close() }()
+	version, err := srcDB.Version()
+	if err != nil {
+		return err
+	}
+	schema, err := readCopySchema(srcDB)
+	if err != nil {
+		return err
+	}
+	dstDB, err := f.openCopyDestination(ctx, dst, version, schema)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dstDB.Close() }()
+
+	for _, store := range schema {
+		if err := copyObjectStore(ctx, srcDB, dstDB, store, options); err != nil {
+			return err
+		}
+	}
+	if !options.DeleteSource {
+		return nil
+	}
+	if err := srcDB.Close(); err != nil {
+		return err
+	}
+	req, err := f.DeleteDatabase(src)
+	if err != nil {
+		return err
+	}
+	return req.Await(ctx)
+}
+
+// openCopySource opens the existing database name, without creating it
+func (f *Factory) openCopySource(ctx context.Context, name string) (*Database, error) {
+	notFound := false
+	req, err := f.Open(ctx, name, 0, func(db *Database, oldVersion, newVersion uint) error {
+		// closing the connection aborts the upgrade, so the database isn't created
+		notFound = true
+		return db.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err := req.Await(ctx)
+	if notFound {
+		return nil, errCopySourceNotFound
+	}
+	return db, err
+}
+
+// openCopyDestination creates the database name with the given version and schema, or opens it if a previous copy already created it
+func (f *Factory) openCopyDestination(ctx context.Context, name string, version uint, schema []copyStoreSchema) (*Database, error) {
+	exists := false
+	req, err := f.Open(ctx, name, version, func(db *Database, oldVersion, newVersion uint) error {
+		if oldVersion != 0 {
+			exists = true
+			return db.Close()
+		}
+		return createCopySchema(db, schema)
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err := req.Await(ctx)
+	if exists {
+		return nil, errCopyDestinationExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	dstSchema, err := readCopySchema(db)
+	if err == nil {
+		var equal bool
+		equal, err = equalCopySchema(dstSchema, schema)
+		if err == nil && !equal {
+			err = errCopyDestinationExists
+		}
+	}
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// equalCopySchema returns true if a and b have the same object stores, key paths, key generators, and indexes
+func equalCopySchema(a, b []copyStoreSchema) (bool, error) {
+	if len(a) != len(b) {
+		return false, nil
+	}
+	for i := range a {
+		storeA, storeB := a[i], b[i]
+		if storeA.name != storeB.name || storeA.autoIncrement != storeB.autoIncrement || len(storeA.indexes) != len(storeB.indexes) {
+			return false, nil
+		}
+		if equal, err := equalKeyPath(storeA.keyPath, storeB.keyPath); err != nil || !equal {
+			return false, err
+		}
+		for j := range storeA.indexes {
+			indexA, indexB := storeA.indexes[j], storeB.indexes[j]
+			if indexA.name != indexB.name || indexA.unique != indexB.unique || indexA.multiEntry != indexB.multiEntry {
+				return false, nil
+			}
+			if equal, err := equalKeyPath(indexA.keyPath, indexB.keyPath); err != nil || !equal {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// equalKeyPath returns true if a and b are both null, the same string, or arrays of the same strings
+func equalKeyPath(a, b js.Value) (bool, error) {
+	safeA, safeB := safejs.Safe(a), safejs.Safe(b)
+	if safeA.Type() != safeB.Type() {
+		return false, nil
+	}
+	switch safeA.Type() {
+	case safejs.TypeString:
+		strA, err := safeA.String()
+		if err != nil {
+			return false, err
+		}
+		strB, err := safeB.String()
+		return strA == strB, err
+	case safejs.TypeObject:
+		strsA, err := stringsFromArray(safeA)
+		if err != nil {
+			return false, err
+		}
+		strsB, err := stringsFromArray(safeB)
+		if err != nil || len(strsA) != len(strsB) {
+			return false, err
+		}
+		for i := range strsA {
+			if strsA[i] != strsB[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+	default:
+		return true, nil
+	}
+}
+
+func readCopySchema(db *Database) ([]copyStoreSchema, error) {
+	names, err := db.ObjectStoreNames()
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+	txn, err := db.Transaction(TransactionReadOnly, names[0], names[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	schema := make([]copyStoreSchema, 0, len(names))
+	for _, name := range names {
+		store, err := txn.ObjectStore(name)
+		if err != nil {
+			return nil, err
+		}
+		storeSchema := copyStoreSchema{name: name}
+		storeSchema.keyPath, err = store.KeyPath()
+		if err != nil {
+			return nil, err
+		}
+		storeSchema.autoIncrement, err = store.AutoIncrement()
+		if err != nil {
+			return nil, err
+		}
+		indexNames, err := store.IndexNames()
+		if err != nil {
+			return nil, err
+		}
+		for _, indexName := range indexNames {
+			index, err := store.Index(indexName)
+			if err != nil {
+				return nil, err
+			}
+			indexSchema := copyIndexSchema{name: indexName}
+			indexSchema.keyPath, err = index.KeyPath()
+			if err != nil {
+				return nil, err
+			}
+			indexSchema.unique, err = index.Unique()
+			if err != nil {
+				return nil, err
+			}
+			indexSchema.multiEntry, err = index.MultiEntry()
+			if err != nil {
+				return nil, err
+			}
+			storeSchema.indexes = append(storeSchema.indexes, indexSchema)
+		}
+		schema = append(schema, storeSchema)
+	}
+	return schema, nil
+}
+
+func createCopySchema(db *Database, schema []copyStoreSchema) error {
+	for _, storeSchema := range schema {
+		store, err := db.CreateObjectStore(storeSchema.name, ObjectStoreOptions{
+			KeyPath:       storeSchema.keyPath,
+			AutoIncrement: storeSchema.autoIncrement,
+		})
+		if err != nil {
+			return err
+		}
+		for _, indexSchema := range storeSchema.indexes {
+			_, err := store.CreateIndex(indexSchema.name, indexSchema.keyPath, IndexOptions{
+				Unique:     indexSchema.unique,
+				MultiEntry: indexSchema.multiEntry,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyObjectStore copies records from srcDB to dstDB in batches, starting after dstDB's last key
+func copyObjectStore(ctx context.Context, srcDB, dstDB *Database, schema copyStoreSchema, options CopyOptions) error {
+	total, err := countRecords(ctx, srcDB, schema.name)
+	if err != nil {
+		return err
+	}
+	copied, lastKey, err := copyDestinationState(ctx, dstDB, schema.name)
+	if err != nil {
+		return err
+	}
+	inlineKeys := !schema.keyPath.IsNull() && !schema.keyPath.IsUndefined()
+	for {
+		keys, values, err := readCopyBatch(ctx, srcDB, schema.name, lastKey, options.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := writeCopyBatch(ctx, dstDB, schema.name, inlineKeys, keys, values); err != nil {
+			return err
+		}
+		copied += uint(len(keys))
+		lastKey = keys[len(keys)-1]
+		if options.Progress != nil {
+			options.Progress(CopyProgress{
+				ObjectStore: schema.name,
+				Copied:      copied,
+				Total:       total,
+			})
+		}
+		if len(keys) < options.BatchSize {
+			return nil
+		}
+	}
+}
+
+func countRecords(ctx context.Context, db *Database, storeName string) (uint, error) {
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	if err != nil {
+		return 0, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return 0, err
+	}
+	req, err := store.Count()
+	if err != nil {
+		return 0, err
+	}
+	return req.Await(ctx)
+}
+
+// copyDestinationState returns the number of records already copied and the last copied key, which is undefined if nothing was copied
+func copyDestinationState(ctx context.Context, db *Database, storeName string) (uint, js.Value, error) {
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	if err != nil {
+		return 0, js.Value{}, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return 0, js.Value{}, err
+	}
+	countReq, err := store.Count()
+	if err != nil {
+		return 0, js.Value{}, err
+	}
+	cursorReq, err := store.OpenKeyCursor(CursorPrevious)
+	if err != nil {
+		return 0, js.Value{}, err
+	}
+	lastKey := js.Undefined()
+	err = cursorReq.Iter(ctx, func(cursor *Cursor) error {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		lastKey = key
+		return ErrCursorStopIter
+	})
+	if err != nil {
+		return 0, js.Value{}, err
+	}
+	count, err := countReq.Await(ctx)
+	return count, lastKey, err
+}
+
+// readCopyBatch reads up to batchSize records from the store, starting after lastKey if it is defined
+func readCopyBatch(ctx context.Context, db *Database, storeName string, lastKey js.Value, batchSize int) (keys, values []js.Value, err error) {
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var req *CursorWithValueRequest
+	if lastKey.IsUndefined() {
+		req, err = store.OpenCursor(CursorNext)
+	} else {
+		var keyRange *KeyRange
+		keyRange, err = NewKeyRangeLowerBound(lastKey, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		req, err = store.OpenCursorRange(keyRange, CursorNext)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+		key, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+		if len(keys) == batchSize {
+			return ErrCursorStopIter
+		}
+		return nil
+	})
+	return keys, values, err
+}
+
+func writeCopyBatch(ctx context.Context, db *Database, storeName string, inlineKeys bool, keys, values []js.Value) error {
+	txn, err := db.Transaction(TransactionReadWrite, storeName)
+	if err != nil {
+		return err
+	}
+	store, err := txn.ObjectStore(storeName)
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		if inlineKeys {
+			_, err = store.Put(values[i])
+		} else {
+			_, err = store.PutKey(keys[i], values[i])
+		}
+		if err != nil {
+			_ = txn.Abort()
+			return err
+		}
+	}
+	return txn.Await(ctx)
+}
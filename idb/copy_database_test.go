@@ -0,0 +1,246 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func testCopyDB(tb testing.TB) *Database {
+	tb.Helper()
+	db := testDB(tb, func(db *Database) {
+		users, err := db.CreateObjectStore("users", ObjectStoreOptions{KeyPath: js.ValueOf("id")})
+		assert.NoError(tb, err)
+		_, err = users.CreateIndex("byEmail", js.ValueOf("email"), IndexOptions{Unique: true})
+		assert.NoError(tb, err)
+		_, err = db.CreateObjectStore("events", ObjectStoreOptions{AutoIncrement: true})
+		assert.NoError(tb, err)
+	})
+	putCopyRecords(tb, db, "a", "b", "c")
+	return db
+}
+
+func putCopyRecords(tb testing.TB, db *Database, ids ...string) {
+	tb.Helper()
+	txn, err := db.Transaction(TransactionReadWrite, "users", "events")
+	assert.NoError(tb, err)
+	users, err := txn.ObjectStore("users")
+	assert.NoError(tb, err)
+	events, err := txn.ObjectStore("events")
+	assert.NoError(tb, err)
+	for _, id := range ids {
+		_, err := users.Put(js.ValueOf(map[string]interface{}{"id": id, "email": id + "@example.com"}))
+		assert.NoError(tb, err)
+		_, err = events.Add(js.ValueOf("created " + id))
+		assert.NoError(tb, err)
+	}
+	assert.NoError(tb, txn.Await(context.Background()))
+}
+
+func testCopyDestination(tb testing.TB, src *Database) string {
+	tb.Helper()
+	name, err := src.Name()
+	assert.NoError(tb, err)
+	dst := name + "-copy"
+	tb.Cleanup(func() {
+		req, err := Global().DeleteDatabase(dst)
+		assert.NoError(tb, err)
+		assert.NoError(tb, req.Await(context.Background()))
+	})
+	return dst
+}
+
+func openCopy(tb testing.TB, name string) *Database {
+	tb.Helper()
+	req, err := Global().Open(context.Background(), name, 0, func(*Database, uint, uint) error {
+		tb.Error("copy should already exist")
+		return nil
+	})
+	assert.NoError(tb, err)
+	db, err := req.Await(context.Background())
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, db.Close())
+	})
+	return db
+}
+
+func getAllCopyKeys(tb testing.TB, db *Database, storeName string) []js.Value {
+	tb.Helper()
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	assert.NoError(tb, err)
+	store, err := txn.ObjectStore(storeName)
+	assert.NoError(tb, err)
+	req, err := store.GetAllKeys()
+	assert.NoError(tb, err)
+	keys, err := req.Await(context.Background())
+	assert.NoError(tb, err)
+	return keys
+}
+
+func TestFactoryCopyDatabase(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := testCopyDB(t)
+	dst := testCopyDestination(t, src)
+
+	var progress []CopyProgress
+	err := Global().CopyDatabase(ctx, mustName(t, src), dst, CopyOptions{
+		BatchSize: 2,
+		Progress: func(p CopyProgress) {
+			progress = append(progress, p)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []CopyProgress{
+		{ObjectStore: "events", Copied: 2, Total: 3},
+		{ObjectStore: "events", Copied: 3, Total: 3},
+		{ObjectStore: "users", Copied: 2, Total: 3},
+		{ObjectStore: "users", Copied: 3, Total: 3},
+	}, progress)
+
+	db := openCopy(t, dst)
+	version, err := db.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), version)
+	assert.Equal(t, getAllCopyKeys(t, src, "users"), getAllCopyKeys(t, db, "users"))
+	assert.Equal(t, getAllCopyKeys(t, src, "events"), getAllCopyKeys(t, db, "events"))
+
+	txn, err := db.Transaction(TransactionReadOnly, "users")
+	assert.NoError(t, err)
+	users, err := txn.ObjectStore("users")
+	assert.NoError(t, err)
+	index, err := users.Index("byEmail")
+	assert.NoError(t, err)
+	unique, err := index.Unique()
+	assert.NoError(t, err)
+	assert.Equal(t, true, unique)
+	req, err := index.Get(js.ValueOf("b@example.com"))
+	assert.NoError(t, err)
+	user, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", user.Get("id").String())
+}
+
+func TestFactoryCopyDatabaseResume(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := testCopyDB(t)
+	dst := testCopyDestination(t, src)
+	assert.NoError(t, Global().CopyDatabase(ctx, mustName(t, src), dst, CopyOptions{}))
+
+	putCopyRecords(t, src, "d")
+	var progress []CopyProgress
+	err := Global().CopyDatabase(ctx, mustName(t, src), dst, CopyOptions{
+		Progress: func(p CopyProgress) {
+			progress = append(progress, p)
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []CopyProgress{
+		{ObjectStore: "events", Copied: 4, Total: 4},
+		{ObjectStore: "users", Copied: 4, Total: 4},
+	}, progress)
+	db := openCopy(t, dst)
+	assert.Equal(t, getAllCopyKeys(t, src, "users"), getAllCopyKeys(t, db, "users"))
+}
+
+func TestFactoryCopyDatabaseDeleteSource(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := testCopyDB(t)
+	srcName := mustName(t, src)
+	dst := testCopyDestination(t, src)
+
+	err := Global().CopyDatabase(ctx, srcName, dst, CopyOptions{DeleteSource: true})
+	assert.NoError(t, err)
+	_, err = Global().openCopySource(ctx, srcName)
+	assert.ErrorIs(t, err, errCopySourceNotFound)
+	assert.Equal(t, 3, len(getAllCopyKeys(t, openCopy(t, dst), "users")))
+}
+
+func TestFactoryCopyDatabaseErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := testCopyDB(t)
+
+	err := Global().CopyDatabase(ctx, mustName(t, src)+"-missing", mustName(t, src)+"-other", CopyOptions{})
+	assert.ErrorIs(t, err, errCopySourceNotFound)
+
+	other := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("other", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	err = Global().CopyDatabase(ctx, mustName(t, src), mustName(t, other), CopyOptions{})
+	assert.ErrorIs(t, err, errCopyDestinationExists)
+}
+
+func TestFactoryCopyDatabaseDifferentSchema(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name         string
+		users        ObjectStoreOptions
+		indexKeyPath string
+		index        IndexOptions
+		events       ObjectStoreOptions
+	}{
+		{
+			name:         "different key path",
+			users:        ObjectStoreOptions{KeyPath: js.ValueOf([]interface{}{"id"})},
+			indexKeyPath: "email",
+			index:        IndexOptions{Unique: true},
+			events:       ObjectStoreOptions{AutoIncrement: true},
+		},
+		{
+			name:         "different key generator",
+			users:        ObjectStoreOptions{KeyPath: js.ValueOf("id")},
+			indexKeyPath: "email",
+			index:        IndexOptions{Unique: true},
+			events:       ObjectStoreOptions{},
+		},
+		{
+			name:         "different index options",
+			users:        ObjectStoreOptions{KeyPath: js.ValueOf("id")},
+			indexKeyPath: "email",
+			index:        IndexOptions{},
+			events:       ObjectStoreOptions{AutoIncrement: true},
+		},
+		{
+			name:         "different index key path",
+			users:        ObjectStoreOptions{KeyPath: js.ValueOf("id")},
+			indexKeyPath: "mail",
+			index:        IndexOptions{Unique: true},
+			events:       ObjectStoreOptions{AutoIncrement: true},
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			src := testCopyDB(t)
+			dst := testDB(t, func(db *Database) {
+				users, err := db.CreateObjectStore("users", tc.users)
+				assert.NoError(t, err)
+				_, err = users.CreateIndex("byEmail", js.ValueOf(tc.indexKeyPath), tc.index)
+				assert.NoError(t, err)
+				_, err = db.CreateObjectStore("events", tc.events)
+				assert.NoError(t, err)
+			})
+			err := Global().CopyDatabase(context.Background(), mustName(t, src), mustName(t, dst), CopyOptions{})
+			assert.ErrorIs(t, err, errCopyDestinationExists)
+		})
+	}
+}
+
+func mustName(tb testing.TB, db *Database) string {
+	tb.Helper()
+	name, err := db.Name()
+	assert.NoError(tb, err)
+	return name
+}
@@ -0,0 +1,107 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package counter stores atomic counters and ID sequences in IndexedDB.
+//
+// Counters are plain numbers under their own keys in an object store with out-of-line keys, so one store can hold many counters. Each change reads and writes the counter in a single read-write transaction, so concurrent tabs never lose an update.
+// Add and Get work inside an existing transaction, to update a counter atomically with other records.
+package counter
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+// Get returns the counter at key in store. Missing counters are 0.
+func Get(ctx context.Context, store *idb.ObjectStore, key js.Value) (int64, error) {
+	req, err := store.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	value, err := req.Await(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if value.IsUndefined() {
+		return 0, nil
+	}
+	n, err := safejs.Safe(value).Float()
+	return int64(n), err
+}
+
+// Add adds delta to the counter at key in store, which must be in a read-write transaction. Returns the new value.
+func Add(ctx context.Context, store *idb.ObjectStore, key js.Value, delta int64) (int64, error) {
+	n, err := Get(ctx, store, key)
+	if err != nil {
+		return 0, err
+	}
+	n += delta
+	return n, set(ctx, store, key, n)
+}
+
+func set(ctx context.Context, store *idb.ObjectStore, key js.Value, n int64) error {
+	value, err := safejs.ValueOf(n)
+	if err != nil {
+		return err
+	}
+	req, err := store.PutKey(key, safejs.Unsafe(value))
+	if err != nil {
+		return err
+	}
+	_, err = req.Await(ctx)
+	return err
+}
+
+// Counter is a counter under a key in an existing object store
+type Counter struct {
+	db        *idb.Database
+	storeName string
+	key       js.Value
+}
+
+// New returns the Counter at key in the object store named storeName
+func New(db *idb.Database, storeName string, key js.Value) *Counter {
+	return &Counter{
+		db:        db,
+		storeName: storeName,
+		key:       key,
+	}
+}
+
+// Value returns the counter's current value
+func (c *Counter) Value(ctx context.Context) (int64, error) {
+	var n int64
+	err := txnutil.RunStore(ctx, c.db, idb.TransactionReadOnly, c.storeName, func(store *idb.ObjectStore) error {
+		var err error
+		n, err = Get(ctx, store, c.key)
+		return err
+	})
+	return n, err
+}
+
+// Add adds delta to the counter. Returns the new value.
+func (c *Counter) Add(ctx context.Context, delta int64) (int64, error) {
+	var n int64
+	err := txnutil.RunStore(ctx, c.db, idb.TransactionReadWrite, c.storeName, func(store *idb.ObjectStore) error {
+		var err error
+		n, err = Add(ctx, store, c.key, delta)
+		return err
+	})
+	return n, err
+}
+
+// Increment adds 1 to the counter. Returns the new value.
+func (c *Counter) Increment(ctx context.Context) (int64, error) {
+	return c.Add(ctx, 1)
+}
+
+// Set replaces the counter's value with n
+func (c *Counter) Set(ctx context.Context, n int64) error {
+	return txnutil.RunStore(ctx, c.db, idb.TransactionReadWrite, c.storeName, func(store *idb.ObjectStore) error {
+		return set(ctx, store, c.key, n)
+	})
+}
@@ -0,0 +1,104 @@
+//go:build js && wasm
+// +build js,wasm
+
+package counter
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testDB(tb testing.TB) *idb.Database {
+	tb.Helper()
+	return idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		if _, err := db.CreateObjectStore("counters", idb.ObjectStoreOptions{}); err != nil {
+			return err
+		}
+		_, err := db.CreateObjectStore("views", idb.ObjectStoreOptions{})
+		return err
+	})
+}
+
+func TestCounter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	counter := New(db, "counters", js.ValueOf("views"))
+
+	n, err := counter.Value(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	n, err = counter.Increment(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = counter.Add(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), n)
+	n, err = counter.Add(ctx, -3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), n)
+
+	assert.NoError(t, counter.Set(ctx, 100))
+	n, err = counter.Value(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), n)
+
+	other := New(db, "counters", js.ValueOf("other"))
+	n, err = other.Value(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	counter := New(db, "counters", js.ValueOf("views"))
+
+	const increments = 20
+	errs := make(chan error, increments)
+	for i := 0; i < increments; i++ {
+		go func() {
+			_, err := counter.Increment(ctx)
+			errs <- err
+		}()
+	}
+	for i := 0; i < increments; i++ {
+		assert.NoError(t, <-errs)
+	}
+	n, err := counter.Value(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(increments), n)
+}
+
+func TestAddInTransaction(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+
+	txn, err := db.Transaction(idb.TransactionReadWrite, "counters", "views")
+	assert.NoError(t, err)
+	counters, err := txn.ObjectStore("counters")
+	assert.NoError(t, err)
+	views, err := txn.ObjectStore("views")
+	assert.NoError(t, err)
+	n, err := Add(ctx, counters, js.ValueOf("views"), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = views.AddKey(js.ValueOf(n), js.ValueOf("page"))
+	assert.NoError(t, err)
+	n, err = Get(ctx, counters, js.ValueOf("views"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.NoError(t, txn.Abort())
+
+	n, err = New(db, "counters", js.ValueOf("views")).Value(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+}
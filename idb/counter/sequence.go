@@ -0,0 +1,78 @@
+//go:build js && wasm
+// +build js,wasm
+
+package counter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+)
+
+// ErrInvalidReserve is returned by Sequence.Reserve when reserving fewer than 1 ID
+var ErrInvalidReserve = errors.New("counter: must reserve at least 1 ID")
+
+// SequenceOptions contains all available options for a Sequence
+type SequenceOptions struct {
+	// BlockSize is the number of IDs reserved at a time by Next. Larger blocks write less often, but unused IDs are skipped once the Sequence is discarded. Defaults to 1.
+	BlockSize int64
+}
+
+// Sequence hands out unique, increasing IDs starting at 1, stored as a counter holding the last reserved ID.
+// IDs are unique across tabs, but only increase within a single Sequence when BlockSize is larger than 1.
+type Sequence struct {
+	counter   *Counter
+	blockSize int64
+
+	mu   sync.Mutex
+	next int64 // next ID to hand out from the reserved block
+	last int64 // last ID in the reserved block
+}
+
+// NewSequence returns the Sequence at key in the object store named storeName
+func NewSequence(db *idb.Database, storeName string, key js.Value, options SequenceOptions) *Sequence {
+	if options.BlockSize <= 0 {
+		options.BlockSize = 1
+	}
+	return &Sequence{
+		counter:   New(db, storeName, key),
+		blockSize: options.BlockSize,
+	}
+}
+
+// Next returns the next ID, reserving a new block of IDs if the current one is used up
+func (s *Sequence) Next(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next == 0 || s.next > s.last {
+		first, err := s.Reserve(ctx, s.blockSize)
+		if err != nil {
+			return 0, err
+		}
+		s.next = first
+		s.last = first + s.blockSize - 1
+	}
+	id := s.next
+	s.next++
+	return id, nil
+}
+
+// Reserve reserves n consecutive IDs, independent of the block used by Next. Returns the first ID.
+func (s *Sequence) Reserve(ctx context.Context, n int64) (int64, error) {
+	if n < 1 {
+		return 0, ErrInvalidReserve
+	}
+	last, err := s.counter.Add(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	return last - n + 1, nil
+}
+
+// Last returns the last reserved ID across all Sequences with this key, or 0 if none were reserved
+func (s *Sequence) Last(ctx context.Context) (int64, error) {
+	return s.counter.Value(ctx)
+}
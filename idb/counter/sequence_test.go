@@ -0,0 +1,78 @@
+//go:build js && wasm
+// +build js,wasm
+
+package counter
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestSequenceNext(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name       string
+		blockSize  int64
+		expectLast int64
+	}{
+		{name: "default block size", blockSize: 0, expectLast: 5},
+		{name: "block size 2", blockSize: 2, expectLast: 6},
+		{name: "block size 10", blockSize: 10, expectLast: 10},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			db := testDB(t)
+			seq := NewSequence(db, "counters", js.ValueOf("ids"), SequenceOptions{BlockSize: tc.blockSize})
+
+			for expect := int64(1); expect <= 5; expect++ {
+				id, err := seq.Next(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, expect, id)
+			}
+			last, err := seq.Last(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectLast, last)
+		})
+	}
+}
+
+func TestSequenceShared(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	seq1 := NewSequence(db, "counters", js.ValueOf("ids"), SequenceOptions{BlockSize: 3})
+	seq2 := NewSequence(db, "counters", js.ValueOf("ids"), SequenceOptions{BlockSize: 3})
+
+	var ids []int64
+	for _, seq := range []*Sequence{seq1, seq2, seq1, seq2} {
+		id, err := seq.Next(ctx)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []int64{1, 4, 2, 5}, ids)
+}
+
+func TestSequenceReserve(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	seq := NewSequence(db, "counters", js.ValueOf("ids"), SequenceOptions{})
+
+	first, err := seq.Reserve(ctx, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), first)
+	first, err = seq.Reserve(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), first)
+	id, err := seq.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(111), id)
+
+	_, err = seq.Reserve(ctx, 0)
+	assert.Equal(t, ErrInvalidReserve, err)
+}
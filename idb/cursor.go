@@ -163,6 +163,7 @@ func (c *Cursor) Delete() (*AckRequest, error) {
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
+	c.txn.trackCursorKey(c.jsCursor)
 	req := wrapRequest(c.txn, reqValue)
 	return newAckRequest(req), nil
 }
@@ -173,7 +174,9 @@ func (c *Cursor) Update(value js.Value) (*Request, error) {
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
-	return wrapRequest(c.txn, reqValue), nil
+	req := wrapRequest(c.txn, reqValue)
+	c.txn.trackCursorRequest(c.jsCursor, req)
+	return req, nil
 }
 
 // CursorWithValue represents a cursor for traversing or iterating over multiple records in a database. It is the same as the Cursor, except that it includes the value property.
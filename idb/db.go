@@ -4,6 +4,8 @@
 package idb
 
 import (
+	"sync"
+
 	"github.com/hack-pad/go-indexeddb/idb/internal/jscache"
 	"github.com/hack-pad/safejs"
 )
@@ -12,6 +14,10 @@ import (
 type Database struct {
 	jsDB        safejs.Value
 	callStrings jscache.Strings
+	upgradeTxn  *Transaction // the version change transaction, set while upgrading
+
+	changeFeedMu sync.Mutex
+	changeFeed   *changeFeed
 }
 
 func wrapDatabase(jsDB safejs.Value) *Database {
@@ -55,17 +61,35 @@ func (db *Database) CreateObjectStore(name string, options ObjectStoreOptions) (
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
-	return wrapObjectStore(nil, jsObjectStore), nil
+	store := wrapObjectStore(db.upgradeTxn, jsObjectStore)
+	if db.upgradeTxn != nil {
+		db.upgradeTxn.objectStores[name] = store
+	}
+	return store, nil
 }
 
 // DeleteObjectStore destroys the object store with the given name in the connected database, along with any indexes that reference it.
 func (db *Database) DeleteObjectStore(name string) error {
 	_, err := db.jsDB.Call("deleteObjectStore", name)
+	if err == nil && db.upgradeTxn != nil {
+		delete(db.upgradeTxn.objectStores, name)
+	}
 	return tryAsDOMException(err)
 }
 
+// UpgradeTransaction returns the version change transaction while the database is upgrading, like inside an Upgrader. Use it to access existing object stores during an upgrade.
+func (db *Database) UpgradeTransaction() (*Transaction, error) {
+	if db.upgradeTxn == nil {
+		return nil, errNotVersionChange
+	}
+	return db.upgradeTxn, nil
+}
+
 // Close closes the connection to a database.
 func (db *Database) Close() error {
+	if err := db.closeChangeFeed(); err != nil {
+		return err
+	}
 	_, err := db.jsDB.Call("close")
 	return tryAsDOMException(err)
 }
@@ -83,6 +107,9 @@ type TransactionOptions struct {
 
 // TransactionWithOptions returns a transaction object containing the Transaction.ObjectStore() method, which you can use to access your object store.
 func (db *Database) TransactionWithOptions(options TransactionOptions, objectStoreName string, objectStoreNames ...string) (*Transaction, error) {
+	if options.Mode == TransactionVersionChange {
+		return nil, errVersionChangeMode
+	}
 	objectStoreNames = append([]string{objectStoreName}, objectStoreNames...) // require at least one name
 
 	optionsMap := make(map[string]interface{})
@@ -99,5 +126,17 @@ func (db *Database) TransactionWithOptions(options TransactionOptions, objectSto
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
-	return wrapTransaction(db, jsTxn), nil
+	txn := wrapTransaction(db, jsTxn)
+	if options.Mode == TransactionReadWrite {
+		name, err := db.Name()
+		if err != nil {
+			return nil, err
+		}
+		if feed := db.getChangeFeed(); feed != nil || localChanges.has(name) {
+			if err := txn.publishChangesOnComplete(feed, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return txn, nil
 }
@@ -41,6 +41,28 @@ func testDB(tb testing.TB, initFunc func(*Database)) *Database {
 	return db
 }
 
+// upgradeTestDB closes db, then reopens it with the next version and runs upgrader
+func upgradeTestDB(tb testing.TB, db *Database, upgrader Upgrader) *Database {
+	tb.Helper()
+	name, err := db.Name()
+	assert.NoError(tb, err)
+	version, err := db.Version()
+	assert.NoError(tb, err)
+	assert.NoError(tb, db.Close())
+	req, err := Global().Open(context.Background(), name, version+1, upgrader)
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	db, err = req.Await(context.Background())
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, db.Close())
+	})
+	return db
+}
+
 func TestDatabaseName(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {})
@@ -189,3 +211,66 @@ func TestDatabaseClose(t *testing.T) {
 	_, err = db.Transaction(TransactionReadOnly, "mystore")
 	assert.Error(t, err)
 }
+
+func TestDatabaseUpgradeTransactionAfterUpgrade(t *testing.T) {
+	t.Parallel()
+	var upgradeDB *Database
+	testDB(t, func(db *Database) {
+		upgradeDB = db
+	})
+	_, err := upgradeDB.UpgradeTransaction()
+	assert.ErrorIs(t, err, errNotVersionChange)
+	_, err = upgradeDB.CreateObjectStore("mystore", ObjectStoreOptions{})
+	assert.Error(t, err)
+}
+
+func TestDatabaseTransactionVersionChange(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	_, err := db.Transaction(TransactionVersionChange, "mystore")
+	assert.ErrorIs(t, err, errVersionChangeMode)
+	_, err = db.TransactionWithOptions(TransactionOptions{Mode: TransactionVersionChange}, "mystore")
+	assert.ErrorIs(t, err, errVersionChangeMode)
+}
+
+func TestDatabaseUpgradeTransaction(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		txn, err := db.UpgradeTransaction()
+		assert.NoError(t, err)
+		mode, err := txn.Mode()
+		assert.NoError(t, err)
+		assert.Equal(t, TransactionVersionChange, mode)
+
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		txnStore, err := txn.ObjectStore("mystore")
+		assert.NoError(t, err)
+		assert.Equal(t, store, txnStore)
+	})
+	_, err := db.UpgradeTransaction()
+	assert.ErrorIs(t, err, errNotVersionChange)
+
+	db = upgradeTestDB(t, db, func(db *Database, oldVersion, newVersion uint) error {
+		txn, err := db.UpgradeTransaction()
+		if err != nil {
+			return err
+		}
+		store, err := txn.ObjectStore("mystore")
+		if err != nil {
+			return err
+		}
+		_, err = store.CreateIndex("myindex", js.ValueOf("primary"), IndexOptions{})
+		return err
+	})
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	indexNames, err := store.IndexNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"myindex"}, indexNames)
+}
@@ -0,0 +1,73 @@
+//go:build js && wasm
+// +build js,wasm
+
+package encrypt
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
+	"github.com/hack-pad/safejs"
+)
+
+// additionalData is authenticated alongside each record's ciphertext, so an envelope fails to decrypt if it's moved to another key or its unencrypted parts are changed
+type additionalData struct {
+	Version int         `json:"v"`
+	KeyID   string      `json:"kid"`
+	Key     *idbkey.Key `json:"pk"`
+	// Fields are the cleartext fields present when the record was encrypted, as pairs of path and JSON value
+	Fields [][2]string `json:"cf"`
+}
+
+// newAdditionalData returns the additional data for an envelope with the given cleartext fields. primaryKey is only bound if bindKey is true.
+func newAdditionalData(keyID string, bindKey bool, primaryKey js.Value, envelope safejs.Value, fields []string) ([]byte, error) {
+	data := additionalData{
+		Version: envelopeVersion,
+		KeyID:   keyID,
+		Fields:  make([][2]string, 0, len(fields)),
+	}
+	if bindKey {
+		key, err := idb.ParseKey(primaryKey)
+		if err != nil {
+			return nil, err
+		}
+		data.Key = &key
+	}
+	for _, field := range fields {
+		value, _, err := getField(envelope, strings.Split(field, "."))
+		if err != nil {
+			return nil, err
+		}
+		jsonValue, err := jsJSON.Call("stringify", value)
+		if err != nil {
+			return nil, err
+		}
+		var encoded string
+		if !jsonValue.IsUndefined() {
+			encoded, err = jsonValue.String()
+			if err != nil {
+				return nil, err
+			}
+		}
+		data.Fields = append(data.Fields, [2]string{field, encoded})
+	}
+	return json.Marshal(data)
+}
+
+// getField returns the field at path in value, or false if there's no such field
+func getField(value safejs.Value, path []string) (safejs.Value, bool, error) {
+	for _, name := range path {
+		if value.Type() != safejs.TypeObject || value.IsNull() {
+			return safejs.Value{}, false, nil
+		}
+		var err error
+		value, err = value.Get(name)
+		if err != nil {
+			return safejs.Value{}, false, err
+		}
+	}
+	return value, !value.IsUndefined(), nil
+}
@@ -0,0 +1,144 @@
+//go:build js && wasm
+// +build js,wasm
+
+package encrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/promise"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	algorithm = "AES-GCM"
+	ivSize    = 12
+)
+
+var (
+	jsCryptoKey safejs.Value
+	jsSubtle    safejs.Value
+
+	errSubtleUnsupported = errors.New("encrypt: WebCrypto is not supported")
+)
+
+func init() {
+	var err error
+	jsCryptoKey, err = safejs.Global().Get("CryptoKey")
+	if err != nil {
+		panic(err)
+	}
+	crypto, err := safejs.Global().Get("crypto")
+	if err != nil {
+		panic(err)
+	}
+	if !crypto.IsUndefined() {
+		jsSubtle, err = crypto.Get("subtle")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Key is an AES-GCM key used to encrypt records. The ID is stored next to each encrypted record, which identifies the key needed to decrypt it.
+type Key struct {
+	id        string
+	cryptoKey safejs.Value
+}
+
+// ImportKey imports a raw 128 or 256 bit AES-GCM key. The imported key is not extractable, so its bytes can't be read back out of WebCrypto.
+func ImportKey(ctx context.Context, id string, raw []byte) (*Key, error) {
+	if len(raw) != 16 && len(raw) != 32 {
+		return nil, fmt.Errorf("encrypt: invalid key size: %d bytes", len(raw))
+	}
+	if jsSubtle.IsUndefined() {
+		return nil, errSubtleUnsupported
+	}
+	jsRaw, err := idb.BytesToJS(raw)
+	if err != nil {
+		return nil, err
+	}
+	keyPromise, err := jsSubtle.Call("importKey", "raw", jsRaw, algorithm, false, []interface{}{"encrypt", "decrypt"})
+	if err != nil {
+		return nil, err
+	}
+	cryptoKey, err := promise.Await(ctx, keyPromise)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{id: id, cryptoKey: cryptoKey}, nil
+}
+
+// WrapKey wraps an existing AES-GCM CryptoKey, like one derived with crypto.subtle.deriveKey. The key must be usable for both encrypt and decrypt.
+func WrapKey(id string, cryptoKey js.Value) (*Key, error) {
+	if jsCryptoKey.IsUndefined() {
+		return nil, errSubtleUnsupported
+	}
+	isKey, err := safejs.Safe(cryptoKey).InstanceOf(jsCryptoKey)
+	if err != nil {
+		return nil, err
+	}
+	if !isKey {
+		return nil, errors.New("encrypt: value is not a CryptoKey")
+	}
+	return &Key{id: id, cryptoKey: safejs.Safe(cryptoKey)}, nil
+}
+
+// ID returns the key's ID
+func (k *Key) ID() string {
+	return k.id
+}
+
+// seal encrypts plaintext with a new random IV. additionalData is authenticated, but not encrypted, and must be passed to open unchanged.
+func (k *Key) seal(ctx context.Context, plaintext, additionalData []byte) (iv, ciphertext []byte, err error) {
+	iv = make([]byte, ivSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err = k.call(ctx, "encrypt", iv, plaintext, additionalData)
+	return iv, ciphertext, err
+}
+
+// open decrypts and authenticates ciphertext and additionalData
+func (k *Key) open(ctx context.Context, iv, ciphertext, additionalData []byte) ([]byte, error) {
+	plaintext, err := k.call(ctx, "decrypt", iv, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return plaintext, nil
+}
+
+func (k *Key) call(ctx context.Context, method string, iv, data, additionalData []byte) ([]byte, error) {
+	jsIV, err := idb.BytesToJS(iv)
+	if err != nil {
+		return nil, err
+	}
+	jsData, err := idb.BytesToJS(data)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{
+		"name": algorithm,
+		"iv":   jsIV,
+	}
+	if additionalData != nil {
+		params["additionalData"], err = idb.BytesToJS(additionalData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resultPromise, err := jsSubtle.Call(method, params, k.cryptoKey, jsData)
+	if err != nil {
+		return nil, err
+	}
+	result, err := promise.Await(ctx, resultPromise)
+	if err != nil {
+		return nil, err
+	}
+	return idb.BytesFromJS(safejs.Unsafe(result))
+}
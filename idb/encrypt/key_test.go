@@ -0,0 +1,95 @@
+//go:build js && wasm
+// +build js,wasm
+
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func testKey(tb testing.TB, id string, fill byte) *Key {
+	tb.Helper()
+	key, err := ImportKey(context.Background(), id, bytes.Repeat([]byte{fill}, 32))
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	return key
+}
+
+func TestImportKey(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		size      int
+		expectErr bool
+	}{
+		{name: "128 bit", size: 16},
+		{name: "256 bit", size: 32},
+		{name: "invalid size", size: 24, expectErr: true},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			key, err := ImportKey(context.Background(), "mykey", make([]byte, tc.size))
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "mykey", key.ID())
+		})
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	t.Parallel()
+	_, err := WrapKey("mykey", js.ValueOf("not a key"))
+	assert.Error(t, err)
+
+	key := testKey(t, "mykey", 1)
+	wrapped, err := WrapKey("wrapped", safejs.Unsafe(key.cryptoKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "wrapped", wrapped.ID())
+}
+
+func TestKeySealOpen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	key := testKey(t, "mykey", 1)
+	additionalData := []byte("record")
+	iv, ciphertext, err := key.seal(ctx, []byte("secret"), additionalData)
+	assert.NoError(t, err)
+	assert.Equal(t, ivSize, len(iv))
+	assert.Equal(t, false, bytes.Contains(ciphertext, []byte("secret")))
+
+	iv2, ciphertext2, err := key.seal(ctx, []byte("secret"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, false, bytes.Equal(iv, iv2)) // every record gets a new IV
+	assert.Equal(t, false, bytes.Equal(ciphertext, ciphertext2))
+
+	plaintext, err := key.open(ctx, iv, ciphertext, additionalData)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+	plaintext, err = key.open(ctx, iv2, ciphertext2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	_, err = key.open(ctx, iv, ciphertext, []byte("other record"))
+	assert.ErrorIs(t, err, ErrDecrypt)
+	_, err = key.open(ctx, iv, ciphertext, nil)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	ciphertext[0] ^= 0xFF
+	_, err = key.open(ctx, iv, ciphertext, additionalData)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	otherKey := testKey(t, "otherkey", 2)
+	_, err = otherKey.open(ctx, iv2, ciphertext2, nil)
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
@@ -0,0 +1,520 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package encrypt stores values in IndexedDB encrypted at rest, with WebCrypto's AES-GCM.
+//
+// Each record is encrypted with a random IV and stored in an envelope object. Fields listed in Options.CleartextFields are copied into the envelope unencrypted, so key paths and indexes on those fields keep working.
+// The key ID, cleartext fields, and record's primary key are authenticated with the ciphertext, so a record fails to decrypt if any of them change or its ciphertext is copied to another record.
+// Primary keys are only known up front when passed explicitly, so records keyed by a key generator or by a key path are only bound to their primary key through the cleartext fields.
+// WebCrypto is asynchronous, so values are encrypted before each write transaction starts, and decrypted after each read transaction completes.
+package encrypt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	envelopeField   = "$enc"
+	envelopeVersion = 1
+
+	defaultBatchSize = 100
+)
+
+var (
+	jsJSON   safejs.Value
+	jsObject safejs.Value
+
+	// ErrDecrypt is returned when a record fails to decrypt, like if it was encrypted with a different key or was tampered with
+	ErrDecrypt = errors.New("encrypt: failed to decrypt record")
+	// ErrUnknownKey is returned when a record was encrypted with a key which is not in Options.Key or Options.OldKeys
+	ErrUnknownKey = errors.New("encrypt: record encrypted with unknown key")
+)
+
+func init() {
+	var err error
+	jsJSON, err = safejs.Global().Get("JSON")
+	if err != nil {
+		panic(err)
+	}
+	jsObject, err = safejs.Global().Get("Object")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Options contains all available options for an encrypted Store
+type Options struct {
+	// Key encrypts new records. Required.
+	Key *Key
+	// OldKeys only decrypt records, like keys being rotated out. See Store.Rotate.
+	OldKeys []*Key
+	// CleartextFields are dot-separated paths to fields copied into each record unencrypted, for use in key paths and indexes. Cleartext fields are readable by anyone with access to the database.
+	CleartextFields []string
+	// BatchSize is the number of records read per transaction by Store.Iter and Store.Rotate. Defaults to 100.
+	BatchSize int
+}
+
+// Store encrypts values in an existing object store. Values must be JSON-compatible.
+type Store struct {
+	db        *idb.Database
+	storeName string
+	options   Options
+	keys      map[string]*Key
+}
+
+// Wrap returns a Store which encrypts values in db's object store named storeName
+func Wrap(db *idb.Database, storeName string, options Options) (*Store, error) {
+	if options.Key == nil {
+		return nil, errors.New("encrypt: Options.Key is required")
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	keys := make(map[string]*Key, len(options.OldKeys)+1)
+	for _, key := range options.OldKeys {
+		keys[key.id] = key
+	}
+	keys[options.Key.id] = options.Key
+	return &Store{
+		db:        db,
+		storeName: storeName,
+		options:   options,
+		keys:      keys,
+	}, nil
+}
+
+// Get returns the decrypted value for key, or undefined if key does not exist
+func (s *Store) Get(ctx context.Context, key js.Value) (js.Value, error) {
+	var value js.Value
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := store.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = req.Await(ctx)
+		return err
+	})
+	if err != nil {
+		return js.Value{}, err
+	}
+	return s.Decrypt(ctx, key, value)
+}
+
+// Add encrypts value, then adds it with the given key. key may be undefined for stores with a key generator, or with a key path in CleartextFields. Returns the new record's key.
+func (s *Store) Add(ctx context.Context, key, value js.Value) (js.Value, error) {
+	return s.write(ctx, key, value, func(store *idb.ObjectStore, encrypted js.Value) (*idb.Request, error) {
+		req, err := store.AddKey(key, encrypted)
+		if err != nil {
+			return nil, err
+		}
+		return req.Request, nil
+	})
+}
+
+// Put encrypts value, then puts it with the given key. key may be undefined for stores with a key generator, or with a key path in CleartextFields. Returns the record's key.
+func (s *Store) Put(ctx context.Context, key, value js.Value) (js.Value, error) {
+	return s.write(ctx, key, value, func(store *idb.ObjectStore, encrypted js.Value) (*idb.Request, error) {
+		return store.PutKey(key, encrypted)
+	})
+}
+
+func (s *Store) write(ctx context.Context, key, value js.Value, writeFn func(*idb.ObjectStore, js.Value) (*idb.Request, error)) (js.Value, error) {
+	encrypted, err := s.Encrypt(ctx, key, value)
+	if err != nil {
+		return js.Value{}, err
+	}
+	var resultKey js.Value
+	err = txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := writeFn(store, encrypted)
+		if err != nil {
+			return err
+		}
+		resultKey, err = req.Await(ctx)
+		return err
+	})
+	return resultKey, err
+}
+
+// Delete deletes the record for key
+func (s *Store) Delete(ctx context.Context, key js.Value) error {
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		_, err := store.Delete(key)
+		return err
+	})
+}
+
+// Iter calls fn with the key and decrypted value of every record in keyRange, in key order. A nil keyRange iterates all records.
+// Records are read in batches of Options.BatchSize per transaction, then decrypted outside the transaction, so writes between batches may be observed. Stops if fn returns an error, and returns that error.
+func (s *Store) Iter(ctx context.Context, keyRange *idb.KeyRange, fn func(key, value js.Value) error) error {
+	return s.iterBatches(ctx, keyRange, func(keys, values []js.Value) error {
+		for i, key := range keys {
+			value, err := s.Decrypt(ctx, key, values[i])
+			if err != nil {
+				return err
+			}
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Rotate re-encrypts every record with Options.Key which was encrypted with one of Options.OldKeys, or which is not encrypted yet.
+// To rotate keys, Wrap the store with the new Key and the previous keys in OldKeys, then call Rotate. Once it returns, the old keys are no longer needed.
+//
+// Records are walked with a cursor in batches of Options.BatchSize. Records changed by another writer during a batch are left as-is, so run Rotate again if other connections may still write with an old key.
+func (s *Store) Rotate(ctx context.Context) error {
+	return s.iterBatches(ctx, nil, func(keys, values []js.Value) error {
+		var rotateKeys, oldValues, newValues []js.Value
+		for i, value := range values {
+			keyID, encrypted, err := envelopeKeyID(safejs.Safe(value))
+			if err != nil {
+				return err
+			}
+			if encrypted && keyID == s.options.Key.id {
+				continue
+			}
+			decrypted, err := s.Decrypt(ctx, keys[i], value)
+			if err != nil {
+				return err
+			}
+			reencrypted, err := s.Encrypt(ctx, keys[i], decrypted)
+			if err != nil {
+				return err
+			}
+			rotateKeys = append(rotateKeys, keys[i])
+			oldValues = append(oldValues, value)
+			newValues = append(newValues, reencrypted)
+		}
+		if len(rotateKeys) == 0 {
+			return nil
+		}
+		return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+			keyPath, err := store.KeyPath()
+			if err != nil {
+				return err
+			}
+			inlineKeys := !keyPath.IsNull()
+			for i, key := range rotateKeys {
+				req, err := store.Get(key)
+				if err != nil {
+					return err
+				}
+				current, err := req.Await(ctx)
+				if err != nil {
+					return err
+				}
+				unchanged, err := sameRecord(safejs.Safe(current), safejs.Safe(oldValues[i]))
+				if err != nil {
+					return err
+				}
+				switch {
+				case !unchanged:
+				case inlineKeys:
+					_, err = store.Put(newValues[i])
+				default:
+					_, err = store.PutKey(key, newValues[i])
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// iterBatches calls fn with the raw keys and values of every record in keyRange, reading up to Options.BatchSize records per transaction
+func (s *Store) iterBatches(ctx context.Context, keyRange *idb.KeyRange, fn func(keys, values []js.Value) error) error {
+	batchRange := keyRange
+	for {
+		var keys, values []js.Value
+		err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+			var req *idb.CursorWithValueRequest
+			var err error
+			if batchRange == nil {
+				req, err = store.OpenCursor(idb.CursorNext)
+			} else {
+				req, err = store.OpenCursorRange(batchRange, idb.CursorNext)
+			}
+			if err != nil {
+				return err
+			}
+			return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+				key, err := cursor.Key()
+				if err != nil {
+					return err
+				}
+				value, err := cursor.Value()
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+				values = append(values, value)
+				if len(keys) == s.options.BatchSize {
+					return idb.ErrCursorStopIter
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys, values); err != nil {
+				return err
+			}
+		}
+		if len(keys) < s.options.BatchSize {
+			return nil
+		}
+		afterLast, err := idb.NewKeyRangeLowerBound(keys[len(keys)-1], true)
+		if err != nil {
+			return err
+		}
+		if keyRange == nil {
+			batchRange = afterLast
+			continue
+		}
+		var ok bool
+		batchRange, ok = keyRange.Intersect(afterLast)
+		if !ok {
+			return nil // the last key was keyRange's upper bound
+		}
+	}
+}
+
+// Encrypt returns an encrypted envelope for value, ready to store at key. Useful for writing inside your own transactions, but encrypt before the transaction starts.
+// key may be undefined if it's not known yet, like for a key generator. Otherwise the envelope only decrypts with the same key.
+func (s *Store) Encrypt(ctx context.Context, key, value js.Value) (js.Value, error) {
+	jsonValue, err := jsJSON.Call("stringify", value)
+	if err != nil {
+		return js.Value{}, err
+	}
+	if jsonValue.IsUndefined() {
+		return js.Value{}, errors.New("encrypt: value has no JSON representation")
+	}
+	plaintext, err := jsonValue.String()
+	if err != nil {
+		return js.Value{}, err
+	}
+	envelope, err := jsObject.New()
+	if err != nil {
+		return js.Value{}, err
+	}
+	var fields []interface{}
+	var fieldNames []string
+	if value.Type() == js.TypeObject && !value.IsNull() {
+		for _, field := range s.options.CleartextFields {
+			path := strings.Split(field, ".")
+			if err := copyField(safejs.Safe(value), envelope, path); err != nil {
+				return js.Value{}, err
+			}
+			if _, ok, err := getField(envelope, path); err != nil {
+				return js.Value{}, err
+			} else if ok {
+				fields = append(fields, field)
+				fieldNames = append(fieldNames, field)
+			}
+		}
+	}
+	bindKey := !key.IsUndefined()
+	additionalData, err := newAdditionalData(s.options.Key.id, bindKey, key, envelope, fieldNames)
+	if err != nil {
+		return js.Value{}, err
+	}
+	iv, ciphertext, err := s.options.Key.seal(ctx, []byte(plaintext), additionalData)
+	if err != nil {
+		return js.Value{}, err
+	}
+	jsIV, err := idb.BytesToJS(iv)
+	if err != nil {
+		return js.Value{}, err
+	}
+	jsCiphertext, err := idb.BytesToJS(ciphertext)
+	if err != nil {
+		return js.Value{}, err
+	}
+	err = envelope.Set(envelopeField, map[string]interface{}{
+		"v":    envelopeVersion,
+		"kid":  s.options.Key.id,
+		"iv":   jsIV,
+		"data": jsCiphertext,
+		"cf":   fields,
+		"pk":   bindKey,
+	})
+	return safejs.Unsafe(envelope), err
+}
+
+// Decrypt returns the decrypted value of an envelope returned by Encrypt, stored at key. Values which are not encrypted are returned unchanged. Useful for values read inside your own transactions, but decrypt after the transaction completes.
+func (s *Store) Decrypt(ctx context.Context, key, value js.Value) (js.Value, error) {
+	envelope, ok, err := getEnvelope(safejs.Safe(value))
+	if err != nil || !ok {
+		return value, err
+	}
+	props := make(map[string]safejs.Value, 6)
+	for _, name := range []string{"v", "kid", "iv", "data", "cf", "pk"} {
+		props[name], err = envelope.Get(name)
+		if err != nil {
+			return js.Value{}, err
+		}
+	}
+	version, err := props["v"].Int()
+	if err != nil {
+		return js.Value{}, err
+	}
+	if version != envelopeVersion {
+		return js.Value{}, fmt.Errorf("encrypt: unsupported envelope version: %d", version)
+	}
+	keyID, err := props["kid"].String()
+	if err != nil {
+		return js.Value{}, err
+	}
+	cryptoKey, ok := s.keys[keyID]
+	if !ok {
+		return js.Value{}, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	additionalData, err := envelopeAdditionalData(safejs.Safe(value), keyID, key, props["cf"], props["pk"])
+	if err != nil {
+		return js.Value{}, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	iv, err := idb.BytesFromJS(safejs.Unsafe(props["iv"]))
+	if err != nil {
+		return js.Value{}, err
+	}
+	ciphertext, err := idb.BytesFromJS(safejs.Unsafe(props["data"]))
+	if err != nil {
+		return js.Value{}, err
+	}
+	plaintext, err := cryptoKey.open(ctx, iv, ciphertext, additionalData)
+	if err != nil {
+		return js.Value{}, err
+	}
+	decrypted, err := jsJSON.Call("parse", string(plaintext))
+	return safejs.Unsafe(decrypted), err
+}
+
+// getEnvelope returns value's encrypted envelope, if it has one
+func getEnvelope(value safejs.Value) (safejs.Value, bool, error) {
+	if value.Type() != safejs.TypeObject || value.IsNull() {
+		return safejs.Value{}, false, nil
+	}
+	envelope, err := value.Get(envelopeField)
+	if err != nil {
+		return safejs.Value{}, false, err
+	}
+	if envelope.Type() != safejs.TypeObject || envelope.IsNull() {
+		return safejs.Value{}, false, nil
+	}
+	return envelope, true, nil
+}
+
+// envelopeAdditionalData returns the additional data authenticated with an envelope's ciphertext, from its stored cleartext field names and primary key flag
+func envelopeAdditionalData(value safejs.Value, keyID string, key js.Value, jsFields, jsBindKey safejs.Value) ([]byte, error) {
+	bindKey, err := jsBindKey.Bool()
+	if err != nil {
+		return nil, err
+	}
+	fieldCount, err := jsFields.Length()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, 0, fieldCount)
+	for i := 0; i < fieldCount; i++ {
+		field, err := jsFields.Index(i)
+		if err != nil {
+			return nil, err
+		}
+		name, err := field.String()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, name)
+	}
+	return newAdditionalData(keyID, bindKey, key, value, fields)
+}
+
+// envelopeKeyID returns the ID of the key which encrypted value, or false if value is not encrypted
+func envelopeKeyID(value safejs.Value) (string, bool, error) {
+	envelope, ok, err := getEnvelope(value)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	keyID, err := envelope.Get("kid")
+	if err != nil {
+		return "", false, err
+	}
+	id, err := keyID.String()
+	return id, err == nil, err
+}
+
+// sameRecord returns true if the raw records a and b are the same, comparing encrypted records by IV since each write uses a new one
+func sameRecord(a, b safejs.Value) (bool, error) {
+	envelopeA, encryptedA, err := getEnvelope(a)
+	if err != nil {
+		return false, err
+	}
+	envelopeB, encryptedB, err := getEnvelope(b)
+	if err != nil {
+		return false, err
+	}
+	if encryptedA != encryptedB {
+		return false, nil
+	}
+	if encryptedA {
+		a, err = envelopeA.Get("iv")
+		if err != nil {
+			return false, err
+		}
+		b, err = envelopeB.Get("iv")
+		if err != nil {
+			return false, err
+		}
+	}
+	jsonA, err := jsJSON.Call("stringify", a)
+	if err != nil {
+		return false, err
+	}
+	jsonB, err := jsJSON.Call("stringify", b)
+	if err != nil {
+		return false, err
+	}
+	return jsonA.Equal(jsonB), nil
+}
+
+// copyField copies the field at path from src into dst, creating intermediate objects as needed. Does nothing if src has no such field.
+func copyField(src, dst safejs.Value, path []string) error {
+	value, err := src.Get(path[0])
+	if err != nil || value.IsUndefined() {
+		return err
+	}
+	if len(path) == 1 {
+		return dst.Set(path[0], value)
+	}
+	if value.Type() != safejs.TypeObject || value.IsNull() {
+		return nil
+	}
+	child, err := dst.Get(path[0])
+	if err != nil {
+		return err
+	}
+	if child.IsUndefined() {
+		child, err = jsObject.New()
+		if err != nil {
+			return err
+		}
+		if err := dst.Set(path[0], child); err != nil {
+			return err
+		}
+	}
+	return copyField(value, child, path[1:])
+}
@@ -0,0 +1,260 @@
+//go:build js && wasm
+// +build js,wasm
+
+package encrypt
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testDB(tb testing.TB) *idb.Database {
+	tb.Helper()
+	return idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		store, err := db.CreateObjectStore("mystore", idb.ObjectStoreOptions{
+			KeyPath: js.ValueOf("id"),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = store.CreateIndex("byEmail", js.ValueOf("user.email"), idb.IndexOptions{})
+		return err
+	})
+}
+
+func testStore(tb testing.TB, db *idb.Database, options Options) *Store {
+	tb.Helper()
+	options.CleartextFields = []string{"id", "user.email"}
+	store, err := Wrap(db, "mystore", options)
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	return store
+}
+
+func testRecord(id string) js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"id":     id,
+		"secret": "password for " + id,
+		"user": map[string]interface{}{
+			"email": id + "@example.com",
+			"name":  "Name " + id,
+		},
+	})
+}
+
+func jsonString(value js.Value) string {
+	return js.Global().Get("JSON").Call("stringify", value).String()
+}
+
+func TestWrapRequiresKey(t *testing.T) {
+	t.Parallel()
+	_, err := Wrap(nil, "mystore", Options{})
+	assert.Error(t, err)
+}
+
+func TestStoreEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, nil, Options{Key: testKey(t, "mykey", 1)})
+	record := testRecord("a")
+	encrypted, err := store.Encrypt(ctx, js.Undefined(), record)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a", encrypted.Get("id").String())
+	assert.Equal(t, "a@example.com", encrypted.Get("user").Get("email").String())
+	assert.Equal(t, true, encrypted.Get("user").Get("name").IsUndefined())
+	assert.Equal(t, true, encrypted.Get("secret").IsUndefined())
+	assert.Equal(t, "mykey", encrypted.Get(envelopeField).Get("kid").String())
+
+	decrypted, err := store.Decrypt(ctx, js.Undefined(), encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, jsonString(record), jsonString(decrypted))
+
+	plain := js.ValueOf(map[string]interface{}{"plain": true})
+	decrypted, err = store.Decrypt(ctx, js.Undefined(), plain)
+	assert.NoError(t, err)
+	assert.Equal(t, true, decrypted.Equal(plain))
+
+	otherStore := testStore(t, nil, Options{Key: testKey(t, "otherkey", 2)})
+	_, err = otherStore.Decrypt(ctx, js.Undefined(), encrypted)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestStoreDecryptTampered(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	sameKeyOtherID := testKey(t, "otherkey", 1)
+	store := testStore(t, nil, Options{Key: testKey(t, "mykey", 1), OldKeys: []*Key{sameKeyOtherID}})
+
+	for _, tc := range []struct {
+		name       string
+		encryptKey js.Value
+		decryptKey js.Value
+		tamper     func(encrypted js.Value)
+	}{
+		{
+			name:   "cleartext field changed",
+			tamper: func(encrypted js.Value) { encrypted.Get("user").Set("email", "b@example.com") },
+		},
+		{
+			name:   "cleartext field removed",
+			tamper: func(encrypted js.Value) { encrypted.Delete("id") },
+		},
+		{
+			name:   "cleartext field list changed",
+			tamper: func(encrypted js.Value) { encrypted.Get(envelopeField).Set("cf", []interface{}{"id"}) },
+		},
+		{
+			name:   "key ID changed",
+			tamper: func(encrypted js.Value) { encrypted.Get(envelopeField).Set("kid", sameKeyOtherID.ID()) },
+		},
+		{
+			name:       "copied to another primary key",
+			encryptKey: js.ValueOf("a"),
+			decryptKey: js.ValueOf("b"),
+			tamper:     func(encrypted js.Value) {},
+		},
+		{
+			name:       "primary key unbound",
+			encryptKey: js.ValueOf("a"),
+			decryptKey: js.ValueOf("a"),
+			tamper:     func(encrypted js.Value) { encrypted.Get(envelopeField).Set("pk", false) },
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			encrypted, err := store.Encrypt(ctx, tc.encryptKey, testRecord("a"))
+			assert.NoError(t, err)
+			_, err = store.Decrypt(ctx, tc.encryptKey, encrypted)
+			assert.NoError(t, err)
+
+			tc.tamper(encrypted)
+			_, err = store.Decrypt(ctx, tc.decryptKey, encrypted)
+			assert.ErrorIs(t, err, ErrDecrypt)
+		})
+	}
+}
+
+func TestStorePutGet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	store := testStore(t, db, Options{Key: testKey(t, "mykey", 1)})
+	key, err := store.Put(ctx, js.Undefined(), testRecord("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, js.ValueOf("a"), key)
+
+	value, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonString(testRecord("a")), jsonString(value))
+
+	_, err = store.Add(ctx, js.Undefined(), testRecord("a"))
+	assert.ErrorIs(t, err, idb.NewDOMException("ConstraintError"))
+
+	// cleartext fields are still indexed
+	txn, err := db.Transaction(idb.TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	objectStore, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	index, err := objectStore.Index("byEmail")
+	assert.NoError(t, err)
+	req, err := index.GetKey(js.ValueOf("a@example.com"))
+	assert.NoError(t, err)
+	primaryKey, err := req.Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, js.ValueOf("a"), primaryKey)
+
+	assert.NoError(t, store.Delete(ctx, js.ValueOf("a")))
+	value, err = store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.IsUndefined())
+}
+
+func TestStoreIter(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		batchSize int
+		keyRange  func() (*idb.KeyRange, error)
+		expect    []string
+	}{
+		{
+			name:      "partial last batch",
+			batchSize: 2,
+			keyRange: func() (*idb.KeyRange, error) {
+				return idb.NewKeyRangeBound(js.ValueOf("b"), js.ValueOf("d"), false, false)
+			},
+			expect: []string{"b", "c", "d"},
+		},
+		{
+			name:      "full last batch ends at upper bound",
+			batchSize: 2,
+			keyRange: func() (*idb.KeyRange, error) {
+				return idb.NewKeyRangeBound(js.ValueOf("b"), js.ValueOf("c"), false, false)
+			},
+			expect: []string{"b", "c"},
+		},
+		{
+			name:      "only key",
+			batchSize: 1,
+			keyRange: func() (*idb.KeyRange, error) {
+				return idb.NewKeyRangeOnly(js.ValueOf("c"))
+			},
+			expect: []string{"c"},
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			store := testStore(t, testDB(t), Options{Key: testKey(t, "mykey", 1), BatchSize: tc.batchSize})
+			for _, id := range []string{"a", "b", "c", "d", "e"} {
+				_, err := store.Put(ctx, js.Undefined(), testRecord(id))
+				assert.NoError(t, err)
+			}
+
+			keyRange, err := tc.keyRange()
+			assert.NoError(t, err)
+			var ids []string
+			err = store.Iter(ctx, keyRange, func(key, value js.Value) error {
+				ids = append(ids, value.Get("id").String())
+				assert.Equal(t, "password for "+key.String(), value.Get("secret").String())
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, ids)
+		})
+	}
+}
+
+func TestStoreRotate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t)
+	oldKey := testKey(t, "old", 1)
+	oldStore := testStore(t, db, Options{Key: oldKey})
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := oldStore.Put(ctx, js.Undefined(), testRecord(id))
+		assert.NoError(t, err)
+	}
+
+	newStore := testStore(t, db, Options{Key: testKey(t, "new", 2), OldKeys: []*Key{oldKey}, BatchSize: 2})
+	assert.NoError(t, newStore.Rotate(ctx))
+
+	rotatedStore := testStore(t, db, Options{Key: testKey(t, "new", 2)}) // old key no longer needed
+	var ids []string
+	err := rotatedStore.Iter(ctx, nil, func(key, value js.Value) error {
+		ids = append(ids, value.Get("id").String())
+		assert.Equal(t, "password for "+key.String(), value.Get("secret").String())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+}
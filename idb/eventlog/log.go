@@ -0,0 +1,197 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package eventlog stores append-only event streams in IndexedDB, for event sourcing.
+//
+// Every event gets a global position from an autoIncrement key, and a sequence number within its stream starting at 1. A unique [stream, seq] index reads a single stream in order, and guarantees concurrent writers can't interleave events in a stream.
+// Snapshots of a stream's state and each projection's last processed position live in side stores, created alongside the log with CreateStores.
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// AnyVersion skips the expected version check in Append
+	AnyVersion int64 = -1
+	// NoStream is the version of a stream without events. Pass it to Append to only create a new stream.
+	NoStream int64 = 0
+
+	// streamIndexName is the name of the unique [stream, seq] index
+	streamIndexName = "byStream"
+	// snapshotSuffix is appended to the log's name to name its snapshot store
+	snapshotSuffix = "-snapshots"
+	// projectionSuffix is appended to the log's name to name its projection position store
+	projectionSuffix = "-projections"
+
+	positionField = "position"
+	streamField   = "stream"
+	seqField      = "seq"
+	dataField     = "data"
+	timeField     = "time"
+)
+
+// ErrWrongVersion is wrapped by every *VersionError. Use errors.Is(err, ErrWrongVersion) to detect any version mismatch.
+var ErrWrongVersion = errors.New("eventlog: wrong expected version")
+
+// VersionError is returned by Append when a stream's version doesn't match the expected version
+type VersionError struct {
+	Stream string
+	// Expected is the version passed to Append
+	Expected int64
+	// Actual is the stream's current version
+	Actual int64
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%v: stream %q expected version %d, but found %d", ErrWrongVersion, e.Stream, e.Expected, e.Actual)
+}
+
+// Unwrap returns ErrWrongVersion
+func (e *VersionError) Unwrap() error {
+	return ErrWrongVersion
+}
+
+// CreateStores creates the object stores for an event log named name. Call it in an Upgrader.
+func CreateStores(db *idb.Database, name string) error {
+	keyPath, err := safejs.ValueOf(positionField)
+	if err != nil {
+		return err
+	}
+	store, err := db.CreateObjectStore(name, idb.ObjectStoreOptions{
+		KeyPath:       safejs.Unsafe(keyPath),
+		AutoIncrement: true,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = store.CreateCompoundIndex(streamIndexName, []string{streamField, seqField}, idb.IndexOptions{Unique: true})
+	if err != nil {
+		return err
+	}
+	if _, err := db.CreateObjectStore(name+snapshotSuffix, idb.ObjectStoreOptions{}); err != nil {
+		return err
+	}
+	_, err = db.CreateObjectStore(name+projectionSuffix, idb.ObjectStoreOptions{})
+	return err
+}
+
+// Log is an event log in object stores created by CreateStores
+type Log struct {
+	db             *idb.Database
+	name           string
+	snapshotName   string
+	projectionName string
+	now            func() time.Time
+}
+
+// Wrap returns the Log named name in db
+func Wrap(db *idb.Database, name string) *Log {
+	return &Log{
+		db:             db,
+		name:           name,
+		snapshotName:   name + snapshotSuffix,
+		projectionName: name + projectionSuffix,
+		now:            time.Now,
+	}
+}
+
+// Append appends events with data to stream, if the stream's version is expectedVersion. Returns the appended events.
+// Pass NoStream to only create a new stream, or AnyVersion to skip the check. Returns a *VersionError on a mismatch.
+func (l *Log) Append(ctx context.Context, stream string, expectedVersion int64, data ...js.Value) ([]Event, error) {
+	events := make([]Event, 0, len(data))
+	err := txnutil.RunStore(ctx, l.db, idb.TransactionReadWrite, l.name, func(store *idb.ObjectStore) error {
+		version, err := streamVersion(ctx, store, stream)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != AnyVersion && version != expectedVersion {
+			return &VersionError{Stream: stream, Expected: expectedVersion, Actual: version}
+		}
+		now := l.now()
+		for i, value := range data {
+			event := Event{
+				Stream: stream,
+				Seq:    version + int64(i) + 1,
+				Data:   value,
+				Time:   now,
+			}
+			record, err := safejs.ValueOf(map[string]interface{}{
+				streamField: event.Stream,
+				seqField:    event.Seq,
+				dataField:   event.Data,
+				timeField:   now.UnixMilli(),
+			})
+			if err != nil {
+				return err
+			}
+			req, err := store.Put(safejs.Unsafe(record)) // record has no position yet, so Put always adds a new event
+			if err != nil {
+				return err
+			}
+			position, err := req.Await(ctx)
+			if err != nil {
+				return err
+			}
+			positionFloat, err := safejs.Safe(position).Float()
+			if err != nil {
+				return err
+			}
+			event.Position = int64(positionFloat)
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Version returns the sequence number of the last event in stream, or NoStream if it has no events
+func (l *Log) Version(ctx context.Context, stream string) (int64, error) {
+	var version int64
+	err := txnutil.RunStore(ctx, l.db, idb.TransactionReadOnly, l.name, func(store *idb.ObjectStore) error {
+		var err error
+		version, err = streamVersion(ctx, store, stream)
+		return err
+	})
+	return version, err
+}
+
+func streamVersion(ctx context.Context, store *idb.ObjectStore, stream string) (int64, error) {
+	index, err := store.Index(streamIndexName)
+	if err != nil {
+		return 0, err
+	}
+	keyRange, err := idb.NewKeyRangeCompoundPrefix(stream)
+	if err != nil {
+		return 0, err
+	}
+	req, err := index.OpenCursorRange(keyRange, idb.CursorPrevious)
+	if err != nil {
+		return 0, err
+	}
+	version := NoStream
+	err = req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		event, err := parseEvent(value)
+		if err != nil {
+			return err
+		}
+		version = event.Seq
+		return idb.ErrCursorStopIter
+	})
+	return version, err
+}
@@ -0,0 +1,89 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testLog(tb testing.TB) *Log {
+	tb.Helper()
+	db := idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		return CreateStores(db, "events")
+	})
+	log := Wrap(db, "events")
+	log.now = func() time.Time { return testNow }
+	return log
+}
+
+func eventData(events []Event) []string {
+	data := make([]string, 0, len(events))
+	for _, event := range events {
+		data = append(data, event.Data.String())
+	}
+	return data
+}
+
+func TestLogAppend(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+
+	events, err := log.Append(ctx, "a", NoStream, js.ValueOf("a1"), js.ValueOf("a2"))
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(events)) {
+		assert.Equal(t, Event{Position: 1, Stream: "a", Seq: 1, Data: events[0].Data, Time: testNow}, events[0])
+		assert.Equal(t, Event{Position: 2, Stream: "a", Seq: 2, Data: events[1].Data, Time: testNow}, events[1])
+	}
+	events, err = log.Append(ctx, "b", NoStream, js.ValueOf("b1"))
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, int64(3), events[0].Position)
+		assert.Equal(t, int64(1), events[0].Seq)
+	}
+	events, err = log.Append(ctx, "a", AnyVersion, js.ValueOf("a3"))
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, int64(3), events[0].Seq)
+	}
+
+	version, err := log.Version(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+	version, err = log.Version(ctx, "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, NoStream, version)
+}
+
+func TestLogAppendExpectedVersion(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+
+	_, err := log.Append(ctx, "a", NoStream, js.ValueOf("a1"))
+	assert.NoError(t, err)
+	_, err = log.Append(ctx, "a", NoStream, js.ValueOf("conflict"))
+	assert.Equal(t, true, errors.Is(err, ErrWrongVersion))
+	var versionErr *VersionError
+	if assert.Equal(t, true, errors.As(err, &versionErr)) {
+		assert.Equal(t, VersionError{Stream: "a", Expected: NoStream, Actual: 1}, *versionErr)
+	}
+	assert.Equal(t, `eventlog: wrong expected version: stream "a" expected version 0, but found 1`, err.Error())
+
+	_, err = log.Append(ctx, "a", 1, js.ValueOf("a2"))
+	assert.NoError(t, err)
+	events, err := log.Read(ctx, "a", ReadOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, eventData(events))
+}
@@ -0,0 +1,91 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+// DefaultProjectBatchSize is the batch size Project uses when batchSize is zero
+const DefaultProjectBatchSize = 100
+
+// Position returns the position of the last event processed by projection, or 0 if it hasn't processed any
+func (l *Log) Position(ctx context.Context, projection string) (int64, error) {
+	key, err := safejs.ValueOf(projection)
+	if err != nil {
+		return 0, err
+	}
+	var position int64
+	err = txnutil.RunStore(ctx, l.db, idb.TransactionReadOnly, l.projectionName, func(store *idb.ObjectStore) error {
+		req, err := store.Get(safejs.Unsafe(key))
+		if err != nil {
+			return err
+		}
+		value, err := req.Await(ctx)
+		if err != nil || value.IsUndefined() {
+			return err
+		}
+		positionFloat, err := safejs.Safe(value).Float()
+		position = int64(positionFloat)
+		return err
+	})
+	return position, err
+}
+
+// SetPosition records position as the last event processed by projection
+func (l *Log) SetPosition(ctx context.Context, projection string, position int64) error {
+	key, err := safejs.ValueOf(projection)
+	if err != nil {
+		return err
+	}
+	value, err := safejs.ValueOf(position)
+	if err != nil {
+		return err
+	}
+	return txnutil.RunStore(ctx, l.db, idb.TransactionReadWrite, l.projectionName, func(store *idb.ObjectStore) error {
+		_, err := store.PutKey(safejs.Unsafe(key), safejs.Unsafe(value))
+		return err
+	})
+}
+
+// Project calls fn with every event after projection's position, in position order, then records the new position. Returns the number of events processed.
+// Events are read in batches of batchSize, and the position is recorded after each batch or failed call to fn, so events can be processed again after a crash.
+func (l *Log) Project(ctx context.Context, projection string, batchSize int, fn func(Event) error) (int, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultProjectBatchSize
+	}
+	position, err := l.Position(ctx, projection)
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for {
+		events, err := l.ReadAll(ctx, position, batchSize)
+		if err != nil {
+			return processed, err
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				if processed > 0 {
+					_ = l.SetPosition(ctx, projection, position)
+				}
+				return processed, err
+			}
+			position = event.Position
+			processed++
+		}
+		if len(events) > 0 {
+			if err := l.SetPosition(ctx, projection, position); err != nil {
+				return processed, err
+			}
+		}
+		if len(events) < batchSize {
+			return processed, nil
+		}
+	}
+}
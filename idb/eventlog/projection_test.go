@@ -0,0 +1,71 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestLogPosition(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+
+	position, err := log.Position(ctx, "totals")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), position)
+	assert.NoError(t, log.SetPosition(ctx, "totals", 7))
+	position, err = log.Position(ctx, "totals")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), position)
+}
+
+func TestLogProject(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+	_, err := log.Append(ctx, "a", NoStream, js.ValueOf("a1"), js.ValueOf("a2"), js.ValueOf("a3"))
+	assert.NoError(t, err)
+	_, err = log.Append(ctx, "b", NoStream, js.ValueOf("b1"), js.ValueOf("b2"))
+	assert.NoError(t, err)
+
+	errProjection := errors.New("projection failed")
+	var seen []string
+	processed, err := log.Project(ctx, "all", 2, func(event Event) error {
+		if event.Data.String() == "b1" {
+			return errProjection
+		}
+		seen = append(seen, event.Data.String())
+		return nil
+	})
+	assert.Equal(t, errProjection, err)
+	assert.Equal(t, 3, processed)
+	assert.Equal(t, []string{"a1", "a2", "a3"}, seen)
+	position, err := log.Position(ctx, "all")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), position)
+
+	seen = nil
+	processed, err = log.Project(ctx, "all", 2, func(event Event) error {
+		seen = append(seen, event.Data.String())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, []string{"b1", "b2"}, seen)
+	position, err = log.Position(ctx, "all")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), position)
+
+	processed, err = log.Project(ctx, "all", 0, func(event Event) error {
+		return errProjection
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, processed)
+}
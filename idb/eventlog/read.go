@@ -0,0 +1,166 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+// Event is an event in a Log
+type Event struct {
+	// Position is the event's global position in the log, which increases with every append
+	Position int64
+	// Stream is the ID of the event's stream
+	Stream string
+	// Seq is the event's sequence number in its stream, starting at 1
+	Seq int64
+	// Data is the event's value passed to Append
+	Data js.Value
+	// Time is when the event was appended
+	Time time.Time
+}
+
+func parseEvent(value js.Value) (Event, error) {
+	jsValue := safejs.Safe(value)
+	var event Event
+	var err error
+	if event.Position, err = intField(jsValue, positionField); err != nil {
+		return Event{}, err
+	}
+	stream, err := jsValue.Get(streamField)
+	if err != nil {
+		return Event{}, err
+	}
+	if event.Stream, err = stream.String(); err != nil {
+		return Event{}, err
+	}
+	if event.Seq, err = intField(jsValue, seqField); err != nil {
+		return Event{}, err
+	}
+	data, err := jsValue.Get(dataField)
+	if err != nil {
+		return Event{}, err
+	}
+	event.Data = safejs.Unsafe(data)
+	millis, err := intField(jsValue, timeField)
+	if err != nil {
+		return Event{}, err
+	}
+	event.Time = time.UnixMilli(millis)
+	return event, nil
+}
+
+func intField(value safejs.Value, name string) (int64, error) {
+	field, err := value.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := field.Float()
+	return int64(n), err
+}
+
+// ReadOptions contains all available options for Log.Read
+type ReadOptions struct {
+	// From is the first sequence number to read, inclusive. Zero reads from the start of the stream, or the end when reading backward.
+	From int64
+	// Direction is idb.CursorNext to read forward, or idb.CursorPrevious to read backward. Defaults to idb.CursorNext.
+	Direction idb.CursorDirection
+	// Limit is the maximum number of events to read. Zero means no limit.
+	Limit int
+}
+
+// Read returns events in stream, in sequence order
+func (l *Log) Read(ctx context.Context, stream string, options ReadOptions) ([]Event, error) {
+	keyRange, err := streamRange(stream, options)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	err = txnutil.RunStore(ctx, l.db, idb.TransactionReadOnly, l.name, func(store *idb.ObjectStore) error {
+		index, err := store.Index(streamIndexName)
+		if err != nil {
+			return err
+		}
+		req, err := index.OpenCursorRange(keyRange, options.Direction)
+		if err != nil {
+			return err
+		}
+		events, err = collect(ctx, req, options.Limit)
+		return err
+	})
+	return events, err
+}
+
+// streamRange returns the range of [stream, seq] index keys to read
+func streamRange(stream string, options ReadOptions) (*idb.KeyRange, error) {
+	keyRange, err := idb.NewKeyRangeCompoundPrefix(stream)
+	if err != nil || options.From <= 0 {
+		return keyRange, err
+	}
+	from, err := idb.NewCompoundKey(stream, options.From)
+	if err != nil {
+		return nil, err
+	}
+	var bound *idb.KeyRange
+	switch options.Direction {
+	case idb.CursorPrevious, idb.CursorPreviousUnique:
+		bound, err = idb.NewKeyRangeUpperBound(from, false)
+	default:
+		bound, err = idb.NewKeyRangeLowerBound(from, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	keyRange, _ = keyRange.Intersect(bound) // bound's key has stream as a prefix, so they always overlap
+	return keyRange, nil
+}
+
+// ReadAll returns events from every stream after position, in position order. Returns up to limit events, or all of them if limit is zero.
+func (l *Log) ReadAll(ctx context.Context, after int64, limit int) ([]Event, error) {
+	lower, err := safejs.ValueOf(after)
+	if err != nil {
+		return nil, err
+	}
+	keyRange, err := idb.NewKeyRangeLowerBound(safejs.Unsafe(lower), true)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	err = txnutil.RunStore(ctx, l.db, idb.TransactionReadOnly, l.name, func(store *idb.ObjectStore) error {
+		req, err := store.OpenCursorRange(keyRange, idb.CursorNext)
+		if err != nil {
+			return err
+		}
+		events, err = collect(ctx, req, limit)
+		return err
+	})
+	return events, err
+}
+
+// collect returns up to limit events from req, or all of them if limit is zero
+func collect(ctx context.Context, req *idb.CursorWithValueRequest, limit int) ([]Event, error) {
+	var events []Event
+	err := req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		event, err := parseEvent(value)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		if limit > 0 && len(events) >= limit {
+			return idb.ErrCursorStopIter
+		}
+		return nil
+	})
+	return events, err
+}
@@ -0,0 +1,105 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestLogRead(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+	_, err := log.Append(ctx, "a", NoStream, js.ValueOf("a1"), js.ValueOf("a2"))
+	assert.NoError(t, err)
+	_, err = log.Append(ctx, "b", NoStream, js.ValueOf("b1"))
+	assert.NoError(t, err)
+	_, err = log.Append(ctx, "a", 2, js.ValueOf("a3"), js.ValueOf("a4"))
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		stream  string
+		options ReadOptions
+		expect  []string
+	}{
+		{
+			name:   "forward",
+			stream: "a",
+			expect: []string{"a1", "a2", "a3", "a4"},
+		},
+		{
+			name:    "forward from",
+			stream:  "a",
+			options: ReadOptions{From: 3},
+			expect:  []string{"a3", "a4"},
+		},
+		{
+			name:    "forward limit",
+			stream:  "a",
+			options: ReadOptions{From: 2, Limit: 2},
+			expect:  []string{"a2", "a3"},
+		},
+		{
+			name:    "backward",
+			stream:  "a",
+			options: ReadOptions{Direction: idb.CursorPrevious},
+			expect:  []string{"a4", "a3", "a2", "a1"},
+		},
+		{
+			name:    "backward from",
+			stream:  "a",
+			options: ReadOptions{From: 2, Direction: idb.CursorPrevious},
+			expect:  []string{"a2", "a1"},
+		},
+		{
+			name:    "backward limit",
+			stream:  "a",
+			options: ReadOptions{Direction: idb.CursorPrevious, Limit: 1},
+			expect:  []string{"a4"},
+		},
+		{
+			name:   "other stream",
+			stream: "b",
+			expect: []string{"b1"},
+		},
+		{
+			name:   "missing stream",
+			stream: "c",
+			expect: []string{},
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			events, err := log.Read(ctx, tc.stream, tc.options)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, eventData(events))
+		})
+	}
+}
+
+func TestLogReadAll(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+	_, err := log.Append(ctx, "a", NoStream, js.ValueOf("a1"))
+	assert.NoError(t, err)
+	_, err = log.Append(ctx, "b", NoStream, js.ValueOf("b1"))
+	assert.NoError(t, err)
+	_, err = log.Append(ctx, "a", 1, js.ValueOf("a2"))
+	assert.NoError(t, err)
+
+	events, err := log.ReadAll(ctx, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "a2"}, eventData(events))
+	events, err = log.ReadAll(ctx, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b1"}, eventData(events))
+}
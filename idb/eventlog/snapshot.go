@@ -0,0 +1,70 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const stateField = "state"
+
+// Snapshot is a stream's state as of an event
+type Snapshot struct {
+	// Seq is the sequence number of the last event applied to State, or NoStream if there's no snapshot
+	Seq int64
+	// State is the stream's state, or undefined if there's no snapshot
+	State js.Value
+}
+
+// SaveSnapshot stores state as stream's state after applying the event at seq, replacing any previous snapshot.
+// Rebuild the state by loading the snapshot, then reading events from seq+1.
+func (l *Log) SaveSnapshot(ctx context.Context, stream string, seq int64, state js.Value) error {
+	key, err := safejs.ValueOf(stream)
+	if err != nil {
+		return err
+	}
+	value, err := safejs.ValueOf(map[string]interface{}{
+		seqField:   seq,
+		stateField: state,
+	})
+	if err != nil {
+		return err
+	}
+	return txnutil.RunStore(ctx, l.db, idb.TransactionReadWrite, l.snapshotName, func(store *idb.ObjectStore) error {
+		_, err := store.PutKey(safejs.Unsafe(key), safejs.Unsafe(value))
+		return err
+	})
+}
+
+// LoadSnapshot returns stream's latest snapshot. Returns a Snapshot with Seq NoStream if there is none.
+func (l *Log) LoadSnapshot(ctx context.Context, stream string) (Snapshot, error) {
+	key, err := safejs.ValueOf(stream)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{Seq: NoStream, State: js.Undefined()}
+	err = txnutil.RunStore(ctx, l.db, idb.TransactionReadOnly, l.snapshotName, func(store *idb.ObjectStore) error {
+		req, err := store.Get(safejs.Unsafe(key))
+		if err != nil {
+			return err
+		}
+		value, err := req.Await(ctx)
+		if err != nil || value.IsUndefined() {
+			return err
+		}
+		jsValue := safejs.Safe(value)
+		if snapshot.Seq, err = intField(jsValue, seqField); err != nil {
+			return err
+		}
+		state, err := jsValue.Get(stateField)
+		snapshot.State = safejs.Unsafe(state)
+		return err
+	})
+	return snapshot, err
+}
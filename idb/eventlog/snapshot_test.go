@@ -0,0 +1,34 @@
+//go:build js && wasm
+// +build js,wasm
+
+package eventlog
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestLogSnapshot(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	log := testLog(t)
+
+	snapshot, err := log.LoadSnapshot(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, NoStream, snapshot.Seq)
+	assert.Equal(t, true, snapshot.State.IsUndefined())
+
+	assert.NoError(t, log.SaveSnapshot(ctx, "a", 2, js.ValueOf(map[string]interface{}{"total": 5})))
+	assert.NoError(t, log.SaveSnapshot(ctx, "a", 4, js.ValueOf(map[string]interface{}{"total": 9})))
+	snapshot, err = log.LoadSnapshot(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), snapshot.Seq)
+	assert.Equal(t, 9, snapshot.State.Get("total").Int())
+
+	snapshot, err = log.LoadSnapshot(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, NoStream, snapshot.Seq)
+}
@@ -0,0 +1,159 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idbfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+var rootRecord = record{mode: fs.ModeDir | 0755}
+
+// record is the metadata stored for each file and directory
+type record struct {
+	mode      fs.FileMode
+	size      int64
+	modTime   time.Time
+	contentID int // key in the contents store, or -1 if the file has no contents yet
+}
+
+func (r record) jsValue() (safejs.Value, error) {
+	return safejs.ValueOf(map[string]interface{}{
+		"mode":      uint32(r.mode),
+		"size":      r.size,
+		"modTime":   r.modTime.UnixMilli(),
+		"contentID": r.contentID,
+	})
+}
+
+func parseRecord(value safejs.Value) (record, error) {
+	var numbers [4]int
+	for i, property := range []string{"mode", "size", "modTime", "contentID"} {
+		propertyValue, err := value.Get(property)
+		if err != nil {
+			return record{}, err
+		}
+		numbers[i], err = propertyValue.Int()
+		if err != nil {
+			return record{}, err
+		}
+	}
+	return record{
+		mode:      fs.FileMode(numbers[0]),
+		size:      int64(numbers[1]),
+		modTime:   time.UnixMilli(int64(numbers[2])),
+		contentID: numbers[3],
+	}, nil
+}
+
+// fileInfo implements fs.FileInfo and fs.DirEntry
+type fileInfo struct {
+	name string
+	record
+}
+
+func newFileInfo(name string, r record) *fileInfo {
+	return &fileInfo{name: path.Base(name), record: r}
+}
+
+func (i *fileInfo) Name() string               { return i.name }
+func (i *fileInfo) Size() int64                { return i.size }
+func (i *fileInfo) Mode() fs.FileMode          { return i.mode }
+func (i *fileInfo) ModTime() time.Time         { return i.modTime }
+func (i *fileInfo) IsDir() bool                { return i.mode.IsDir() }
+func (i *fileInfo) Sys() interface{}           { return nil }
+func (i *fileInfo) Type() fs.FileMode          { return i.mode.Type() }
+func (i *fileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// file is a regular file opened for reading. Its contents are read when opened.
+type file struct {
+	info *fileInfo
+	*bytes.Reader
+}
+
+func newFile(info *fileInfo, contents []byte) *file {
+	return &file{info: info, Reader: bytes.NewReader(contents)}
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *file) Close() error {
+	return nil
+}
+
+// dir is a directory opened for reading. Its entries are read on the first call to ReadDir.
+type dir struct {
+	fs      *FS
+	info    *fileInfo
+	path    string
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errIsDir}
+}
+
+func (d *dir) Close() error {
+	return nil
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fs.ReadDir(d.path)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// fileWriter buffers writes to a file, then saves them on Close
+type fileWriter struct {
+	fs     *FS
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *fileWriter) Write(b []byte) (int, error) {
+	if w.closed {
+		return 0, &fs.PathError{Op: "write", Path: w.name, Err: fs.ErrClosed}
+	}
+	return w.buf.Write(b)
+}
+
+func (w *fileWriter) Close() error {
+	if w.closed {
+		return &fs.PathError{Op: "close", Path: w.name, Err: fs.ErrClosed}
+	}
+	w.closed = true
+	return w.fs.WriteFile(w.name, w.buf.Bytes(), 0666)
+}
@@ -0,0 +1,550 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package idbfs is a persistent, writable file system backed by IndexedDB. It implements fs.FS, fs.ReadDirFS, fs.ReadFileFS, and fs.StatFS.
+//
+// File metadata is stored by path in one object store, and file contents are stored by ID in another. Operations on directory trees, like Rename and RemoveAll, run in a single transaction.
+package idbfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	metadataStore = "metadata"
+	contentsStore = "contents"
+)
+
+var (
+	errIsDir       = errors.New("is a directory")
+	errNotDir      = errors.New("not a directory")
+	errDirNotEmpty = errors.New("directory not empty")
+)
+
+// FS is a file system stored in an IndexedDB database
+type FS struct {
+	db *idb.Database
+}
+
+var (
+	_ fs.FS         = &FS{}
+	_ fs.ReadDirFS  = &FS{}
+	_ fs.ReadFileFS = &FS{}
+	_ fs.StatFS     = &FS{}
+)
+
+// Open opens the file system in the global IndexedDB database named dbName, creating it if it does not exist
+func Open(ctx context.Context, dbName string) (*FS, error) {
+	return OpenFactory(ctx, idb.Global(), dbName)
+}
+
+// OpenFactory is the same as Open, but opens the database in the given factory. Useful for storage buckets.
+func OpenFactory(ctx context.Context, factory *idb.Factory, dbName string) (*FS, error) {
+	req, err := factory.Open(ctx, dbName, 1, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore(metadataStore, idb.ObjectStoreOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = db.CreateObjectStore(contentsStore, idb.ObjectStoreOptions{AutoIncrement: true})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &FS{db: db}, nil
+}
+
+// Close closes the file system's database connection
+func (f *FS) Close() error {
+	return f.db.Close()
+}
+
+// txn is a transaction over both of the file system's object stores
+type txn struct {
+	ctx      context.Context
+	txn      *idb.Transaction
+	metadata *idb.ObjectStore
+	contents *idb.ObjectStore
+}
+
+// run runs fn in a new transaction, then waits for it to complete. If fn returns an error, aborts the transaction and returns the error as an *fs.PathError.
+func (f *FS) run(op, name string, mode idb.TransactionMode, fn func(*txn) error) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	err := f.runTxn(mode, fn)
+	var pathErr *fs.PathError
+	if err != nil && !errors.As(err, &pathErr) {
+		err = &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return err
+}
+
+func (f *FS) runTxn(mode idb.TransactionMode, fn func(*txn) error) error {
+	ctx := context.Background()
+	return txnutil.Run(ctx, f.db, mode, []string{metadataStore, contentsStore}, func(idbTxn *idb.Transaction) error {
+		metadata, err := idbTxn.ObjectStore(metadataStore)
+		if err != nil {
+			return err
+		}
+		contents, err := idbTxn.ObjectStore(contentsStore)
+		if err != nil {
+			return err
+		}
+		return fn(&txn{ctx: ctx, txn: idbTxn, metadata: metadata, contents: contents})
+	})
+}
+
+// stat returns the record for name. Returns fs.ErrNotExist if it does not exist.
+func (t *txn) stat(name string) (record, error) {
+	if name == "." {
+		return rootRecord, nil
+	}
+	jsName, err := jsString(name)
+	if err != nil {
+		return record{}, err
+	}
+	req, err := t.metadata.Get(jsName)
+	if err != nil {
+		return record{}, err
+	}
+	value, err := req.Await(t.ctx)
+	if err != nil {
+		return record{}, err
+	}
+	if safejs.Safe(value).IsUndefined() {
+		return record{}, fs.ErrNotExist
+	}
+	return parseRecord(safejs.Safe(value))
+}
+
+// statDir returns an error if name is not an existing directory
+func (t *txn) statDir(name string) error {
+	r, err := t.stat(name)
+	if err != nil {
+		return err
+	}
+	if !r.mode.IsDir() {
+		return errNotDir
+	}
+	return nil
+}
+
+func (t *txn) putRecord(name string, r record) error {
+	jsName, err := jsString(name)
+	if err != nil {
+		return err
+	}
+	value, err := r.jsValue()
+	if err != nil {
+		return err
+	}
+	_, err = t.metadata.PutKey(jsName, safejs.Unsafe(value))
+	return err
+}
+
+func (t *txn) readContents(r record) ([]byte, error) {
+	contentID, err := jsNumber(r.contentID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := t.contents.GetBytes(contentID)
+	if err != nil {
+		return nil, err
+	}
+	return req.Await(t.ctx)
+}
+
+// subtreeRange returns the key range of every path inside the directory name
+func subtreeRange(name string) (*idb.KeyRange, error) {
+	if name == "." {
+		return idb.NewKeyRangePrefix("")
+	}
+	return idb.NewKeyRangePrefix(name + "/")
+}
+
+// iterSubtree calls fn for every metadata record inside the directory name, in key order
+func (t *txn) iterSubtree(name string, fn func(cursor *idb.CursorWithValue, key string) error) error {
+	keyRange, err := subtreeRange(name)
+	if err != nil {
+		return err
+	}
+	req, err := t.metadata.OpenCursorRange(keyRange, idb.CursorNext)
+	if err != nil {
+		return err
+	}
+	return req.Iter(t.ctx, func(cursor *idb.CursorWithValue) error {
+		jsKey, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		key, err := safejs.Safe(jsKey).String()
+		if err != nil {
+			return err
+		}
+		return fn(cursor, key)
+	})
+}
+
+// Open opens the named file or directory for reading
+func (f *FS) Open(name string) (fs.File, error) {
+	var file fs.File
+	err := f.run("open", name, idb.TransactionReadOnly, func(t *txn) error {
+		r, err := t.stat(name)
+		if err != nil {
+			return err
+		}
+		info := newFileInfo(name, r)
+		if r.mode.IsDir() {
+			file = &dir{fs: f, info: info, path: name}
+			return nil
+		}
+		contents, err := t.readContents(r)
+		file = newFile(info, contents)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Stat returns a fs.FileInfo describing the named file or directory
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	var info fs.FileInfo
+	err := f.run("stat", name, idb.TransactionReadOnly, func(t *txn) error {
+		r, err := t.stat(name)
+		info = newFileInfo(name, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ReadFile reads the named file and returns its contents
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	var contents []byte
+	err := f.run("read", name, idb.TransactionReadOnly, func(t *txn) error {
+		r, err := t.stat(name)
+		if err != nil {
+			return err
+		}
+		if r.mode.IsDir() {
+			return errIsDir
+		}
+		contents, err = t.readContents(r)
+		return err
+	})
+	return contents, err
+}
+
+// ReadDir reads the named directory and returns its entries sorted by file name
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	err := f.run("readdir", name, idb.TransactionReadOnly, func(t *txn) error {
+		if err := t.statDir(name); err != nil {
+			return err
+		}
+		prefix := ""
+		if name != "." {
+			prefix = name + "/"
+		}
+		return t.iterSubtree(name, func(cursor *idb.CursorWithValue, key string) error {
+			childName := strings.TrimPrefix(key, prefix)
+			if i := strings.IndexByte(childName, '/'); i >= 0 {
+				// skip the rest of this child's subtree. '0' is the next character after '/'
+				nextKey, err := jsString(prefix + childName[:i] + "0")
+				if err != nil {
+					return err
+				}
+				return cursor.ContinueKey(nextKey)
+			}
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			r, err := parseRecord(safejs.Safe(value))
+			if err != nil {
+				return err
+			}
+			entries = append(entries, newFileInfo(key, r))
+			return nil
+		})
+	})
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Name() < entries[b].Name()
+	})
+	return entries, err
+}
+
+// Mkdir creates a new directory with the given permission bits. The parent directory must exist.
+func (f *FS) Mkdir(name string, perm fs.FileMode) error {
+	return f.run("mkdir", name, idb.TransactionReadWrite, func(t *txn) error {
+		if err := t.statDir(path.Dir(name)); err != nil {
+			return err
+		}
+		if _, err := t.stat(name); err == nil {
+			return fs.ErrExist
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return t.putRecord(name, record{
+			mode:    fs.ModeDir | perm.Perm(),
+			modTime: time.Now(),
+		})
+	})
+}
+
+// MkdirAll creates a directory and any missing parents with the given permission bits
+func (f *FS) MkdirAll(name string, perm fs.FileMode) error {
+	return f.run("mkdir", name, idb.TransactionReadWrite, func(t *txn) error {
+		return t.mkdirAll(name, perm)
+	})
+}
+
+func (t *txn) mkdirAll(name string, perm fs.FileMode) error {
+	r, err := t.stat(name)
+	switch {
+	case err == nil && r.mode.IsDir():
+		return nil
+	case err == nil:
+		return errNotDir
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	if err := t.mkdirAll(path.Dir(name), perm); err != nil {
+		return err
+	}
+	return t.putRecord(name, record{
+		mode:    fs.ModeDir | perm.Perm(),
+		modTime: time.Now(),
+	})
+}
+
+// WriteFile writes data to the named file, creating it with the given permission bits if necessary. The parent directory must exist.
+func (f *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return f.run("write", name, idb.TransactionReadWrite, func(t *txn) error {
+		return t.writeFile(name, data, perm)
+	})
+}
+
+func (t *txn) writeFile(name string, data []byte, perm fs.FileMode) error {
+	if err := t.statDir(path.Dir(name)); err != nil {
+		return err
+	}
+	r, err := t.stat(name)
+	switch {
+	case err == nil && r.mode.IsDir():
+		return errIsDir
+	case errors.Is(err, fs.ErrNotExist):
+		r = record{mode: perm.Perm(), contentID: -1}
+	case err != nil:
+		return err
+	}
+
+	if r.contentID < 0 {
+		req, err := t.contents.AddBytes(js.Undefined(), data)
+		if err != nil {
+			return err
+		}
+		// AckRequest discards the result, so read the new ID from the underlying request
+		contentID, err := req.Request.Await(t.ctx)
+		if err != nil {
+			return err
+		}
+		r.contentID, err = safejs.Safe(contentID).Int()
+		if err != nil {
+			return err
+		}
+	} else {
+		contentID, err := jsNumber(r.contentID)
+		if err != nil {
+			return err
+		}
+		if _, err := t.contents.PutBytes(contentID, data); err != nil {
+			return err
+		}
+	}
+	r.size = int64(len(data))
+	r.modTime = time.Now()
+	return t.putRecord(name, r)
+}
+
+// Create creates or truncates the named file, and returns a writer for its contents. Contents are saved when the writer is closed.
+func (f *FS) Create(name string) (io.WriteCloser, error) {
+	if err := f.WriteFile(name, nil, 0666); err != nil {
+		return nil, err
+	}
+	return &fileWriter{fs: f, name: name}, nil
+}
+
+// Remove removes the named file or empty directory
+func (f *FS) Remove(name string) error {
+	return f.run("remove", name, idb.TransactionReadWrite, func(t *txn) error {
+		if name == "." {
+			return fs.ErrInvalid
+		}
+		r, err := t.stat(name)
+		if err != nil {
+			return err
+		}
+		if r.mode.IsDir() {
+			empty := true
+			err := t.iterSubtree(name, func(*idb.CursorWithValue, string) error {
+				empty = false
+				return idb.ErrCursorStopIter
+			})
+			if err != nil {
+				return err
+			}
+			if !empty {
+				return errDirNotEmpty
+			}
+		}
+		return t.remove(name, r)
+	})
+}
+
+func (t *txn) remove(name string, r record) error {
+	jsName, err := jsString(name)
+	if err != nil {
+		return err
+	}
+	if _, err := t.metadata.Delete(jsName); err != nil {
+		return err
+	}
+	if r.mode.IsDir() {
+		return nil
+	}
+	contentID, err := jsNumber(r.contentID)
+	if err != nil {
+		return err
+	}
+	_, err = t.contents.Delete(contentID)
+	return err
+}
+
+// RemoveAll removes name and everything inside it in a single transaction. Returns nil if name does not exist.
+func (f *FS) RemoveAll(name string) error {
+	return f.run("removeall", name, idb.TransactionReadWrite, func(t *txn) error {
+		if name == "." {
+			return fs.ErrInvalid
+		}
+		r, err := t.stat(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if r.mode.IsDir() {
+			err := t.iterSubtree(name, func(cursor *idb.CursorWithValue, key string) error {
+				value, err := cursor.Value()
+				if err != nil {
+					return err
+				}
+				child, err := parseRecord(safejs.Safe(value))
+				if err != nil {
+					return err
+				}
+				return t.remove(key, child)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return t.remove(name, r)
+	})
+}
+
+// Rename moves oldname to newname in a single transaction, including everything inside it if oldname is a directory.
+// If newname already exists and both are files, newname is replaced.
+func (f *FS) Rename(oldname, newname string) error {
+	if !fs.ValidPath(newname) {
+		return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrInvalid}
+	}
+	return f.run("rename", oldname, idb.TransactionReadWrite, func(t *txn) error {
+		if oldname == "." || newname == "." || strings.HasPrefix(newname, oldname+"/") {
+			return fs.ErrInvalid
+		}
+		if oldname == newname {
+			_, err := t.stat(oldname)
+			return err
+		}
+		r, err := t.stat(oldname)
+		if err != nil {
+			return err
+		}
+		if err := t.statDir(path.Dir(newname)); err != nil {
+			return &fs.PathError{Op: "rename", Path: newname, Err: err}
+		}
+		existing, err := t.stat(newname)
+		switch {
+		case err == nil && (existing.mode.IsDir() || r.mode.IsDir()):
+			return &fs.PathError{Op: "rename", Path: newname, Err: fs.ErrExist}
+		case err == nil:
+			if err := t.remove(newname, existing); err != nil {
+				return err
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			return err
+		}
+
+		if r.mode.IsDir() {
+			err := t.iterSubtree(oldname, func(cursor *idb.CursorWithValue, key string) error {
+				value, err := cursor.Value()
+				if err != nil {
+					return err
+				}
+				newKey, err := jsString(newname + strings.TrimPrefix(key, oldname))
+				if err != nil {
+					return err
+				}
+				if _, err := t.metadata.PutKey(newKey, value); err != nil {
+					return err
+				}
+				_, err = cursor.Delete()
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		jsOldName, err := jsString(oldname)
+		if err != nil {
+			return err
+		}
+		if _, err := t.metadata.Delete(jsOldName); err != nil {
+			return err
+		}
+		return t.putRecord(newname, r)
+	})
+}
+
+func jsString(s string) (js.Value, error) {
+	value, err := safejs.ValueOf(s)
+	return safejs.Unsafe(value), err
+}
+
+func jsNumber(n int) (js.Value, error) {
+	value, err := safejs.ValueOf(n)
+	return safejs.Unsafe(value), err
+}
@@ -0,0 +1,177 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idbfs
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testFS(tb testing.TB) *FS {
+	tb.Helper()
+	fsys, err := Open(context.Background(), idbtest.DBName(tb))
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, fsys.Close())
+	})
+	return fsys
+}
+
+func readDirNames(tb testing.TB, fsys *FS, name string) []string {
+	tb.Helper()
+	entries, err := fsys.ReadDir(name)
+	assert.NoError(tb, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestFS(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.MkdirAll("a/b", 0755))
+	assert.NoError(t, fsys.Mkdir("a-sibling", 0755))
+	assert.NoError(t, fsys.WriteFile("a/file", []byte("hello"), 0644))
+	assert.NoError(t, fsys.WriteFile("a/b/nested", []byte("world"), 0644))
+	assert.NoError(t, fsys.WriteFile("a-sibling/x", nil, 0644))
+	assert.NoError(t, fsys.WriteFile("top", []byte("top"), 0600))
+
+	assert.NoError(t, fstest.TestFS(fsys, "a/file", "a/b/nested", "a-sibling/x", "top"))
+}
+
+func TestFSReadDir(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.MkdirAll("dir/sub", 0755))
+	assert.NoError(t, fsys.WriteFile("dir/sub/deep", []byte("deep"), 0644))
+	assert.NoError(t, fsys.WriteFile("dir/b", []byte("b"), 0644))
+	assert.NoError(t, fsys.WriteFile("dir/a", []byte("a"), 0644))
+
+	assert.Equal(t, []string{"dir"}, readDirNames(t, fsys, "."))
+	assert.Equal(t, []string{"a", "b", "sub"}, readDirNames(t, fsys, "dir"))
+
+	_, err := fsys.ReadDir("dir/a")
+	assert.Error(t, err)
+	_, err = fsys.ReadDir("missing")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFSWriteFile(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.WriteFile("file", []byte("before"), 0644))
+	assert.NoError(t, fsys.WriteFile("file", []byte("after!"), 0600))
+
+	contents, err := fsys.ReadFile("file")
+	assert.NoError(t, err)
+	assert.Equal(t, "after!", string(contents))
+	info, err := fsys.Stat("file")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), info.Size())
+	assert.Equal(t, fs.FileMode(0644), info.Mode()) // existing permissions are kept
+
+	err = fsys.WriteFile("missing/file", nil, 0644)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NoError(t, fsys.Mkdir("dir", 0755))
+	err = fsys.WriteFile("dir", nil, 0644)
+	assert.Error(t, err)
+}
+
+func TestFSCreate(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	file, err := fsys.Create("file")
+	assert.NoError(t, err)
+	contents, err := fsys.ReadFile("file")
+	assert.NoError(t, err)
+	assert.Zero(t, len(contents))
+
+	_, err = file.Write([]byte("hello "))
+	assert.NoError(t, err)
+	_, err = file.Write([]byte("world"))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	contents, err = fsys.ReadFile("file")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(contents))
+
+	_, err = file.Write([]byte("closed"))
+	assert.ErrorIs(t, err, fs.ErrClosed)
+}
+
+func TestFSMkdir(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.Mkdir("dir", 0700))
+	info, err := fsys.Stat("dir")
+	assert.NoError(t, err)
+	assert.Equal(t, true, info.IsDir())
+	assert.Equal(t, fs.ModeDir|0700, info.Mode())
+
+	assert.ErrorIs(t, fsys.Mkdir("dir", 0700), fs.ErrExist)
+	assert.ErrorIs(t, fsys.Mkdir("missing/dir", 0700), fs.ErrNotExist)
+	assert.NoError(t, fsys.MkdirAll("dir/a/b", 0700))
+	assert.NoError(t, fsys.MkdirAll("dir/a/b", 0700))
+	assert.Equal(t, []string{"b"}, readDirNames(t, fsys, "dir/a"))
+}
+
+func TestFSRemove(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.MkdirAll("dir/sub", 0755))
+	assert.NoError(t, fsys.WriteFile("dir/sub/file", []byte("file"), 0644))
+
+	assert.Error(t, fsys.Remove("dir"))
+	assert.NoError(t, fsys.Remove("dir/sub/file"))
+	assert.NoError(t, fsys.Remove("dir/sub"))
+	assert.ErrorIs(t, fsys.Remove("dir/sub"), fs.ErrNotExist)
+	assert.Equal(t, []string{"dir"}, readDirNames(t, fsys, "."))
+}
+
+func TestFSRemoveAll(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.MkdirAll("dir/sub", 0755))
+	assert.NoError(t, fsys.WriteFile("dir/sub/file", []byte("file"), 0644))
+	assert.NoError(t, fsys.WriteFile("dir-sibling", []byte("sibling"), 0644))
+
+	assert.NoError(t, fsys.RemoveAll("dir"))
+	assert.NoError(t, fsys.RemoveAll("dir"))
+	assert.Equal(t, []string{"dir-sibling"}, readDirNames(t, fsys, "."))
+	_, err := fsys.Stat("dir/sub/file")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFSRename(t *testing.T) {
+	t.Parallel()
+	fsys := testFS(t)
+	assert.NoError(t, fsys.MkdirAll("old/sub", 0755))
+	assert.NoError(t, fsys.WriteFile("old/sub/file", []byte("file"), 0644))
+	assert.NoError(t, fsys.Mkdir("new", 0755))
+
+	assert.NoError(t, fsys.Rename("old", "new/moved"))
+	assert.Equal(t, []string{"new"}, readDirNames(t, fsys, "."))
+	assert.Equal(t, []string{"sub"}, readDirNames(t, fsys, "new/moved"))
+	contents, err := fsys.ReadFile("new/moved/sub/file")
+	assert.NoError(t, err)
+	assert.Equal(t, "file", string(contents))
+
+	assert.ErrorIs(t, fsys.Rename("new", "new/moved/inside"), fs.ErrInvalid)
+	assert.ErrorIs(t, fsys.Rename("missing", "other"), fs.ErrNotExist)
+
+	assert.NoError(t, fsys.WriteFile("other", []byte("other"), 0644))
+	assert.NoError(t, fsys.Rename("new/moved/sub/file", "other"))
+	contents, err = fsys.ReadFile("other")
+	assert.NoError(t, err)
+	assert.Equal(t, "file", string(contents))
+}
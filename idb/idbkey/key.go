@@ -0,0 +1,429 @@
+// Package idbkey provides Go-native IndexedDB keys and key ranges.
+//
+// Unlike the rest of idb, this package does not depend on JavaScript, so keys and ranges can be built, compared, and serialized in any Go program.
+package idbkey
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidKey is returned when a value is not a valid IndexedDB key
+	ErrInvalidKey = errors.New("invalid key")
+)
+
+const (
+	// minDateMillis and maxDateMillis are the smallest and largest time values a JS Date can hold
+	minDateMillis = -8.64e15
+	maxDateMillis = 8.64e15
+)
+
+// Kind is the type of a Key. Kinds are ordered the same way IndexedDB orders key types, so every number is less than every date, and so on.
+type Kind int
+
+const (
+	// KindNumber is a number key
+	KindNumber Kind = iota
+	// KindDate is a date key, with millisecond precision
+	KindDate
+	// KindString is a string key. Strings are ordered by their UTF-16 code units.
+	KindString
+	// KindBinary is a binary key, like an ArrayBuffer or Uint8Array
+	KindBinary
+	// KindArray is an array key of other keys
+	KindArray
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNumber:
+		return "number"
+	case KindDate:
+		return "date"
+	case KindString:
+		return "string"
+	case KindBinary:
+		return "binary"
+	case KindArray:
+		return "array"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Key is a valid IndexedDB key. The zero value is the number 0.
+type Key struct {
+	kind   Kind
+	number float64 // number value, or milliseconds since the Unix epoch for dates
+	str    string
+	binary []byte
+	array  []Key
+}
+
+// Number returns a number key. Panics if n is NaN, which is not a valid key. Use Of to check numbers instead.
+func Number(n float64) Key {
+	if math.IsNaN(n) {
+		panic(fmt.Errorf("%w: NaN", ErrInvalidKey))
+	}
+	return Key{kind: KindNumber, number: n}
+}
+
+// Date returns a date key, truncated to millisecond precision
+func Date(t time.Time) Key {
+	return Key{kind: KindDate, number: float64(t.UnixMilli())}
+}
+
+// String returns a string key
+func String(s string) Key {
+	return Key{kind: KindString, str: s}
+}
+
+// Binary returns a binary key. The bytes are copied.
+func Binary(b []byte) Key {
+	return Key{kind: KindBinary, binary: append([]byte{}, b...)}
+}
+
+// Array returns an array key containing keys
+func Array(keys ...Key) Key {
+	return Key{kind: KindArray, array: append([]Key{}, keys...)}
+}
+
+// Of converts a Go value into a Key.
+// Supports Key, numeric types, string, time.Time, []byte, and []Key or []interface{} arrays of supported values.
+func Of(value interface{}) (Key, error) {
+	switch value := value.(type) {
+	case Key:
+		return value, nil
+	case string:
+		return String(value), nil
+	case time.Time:
+		return Date(value), nil
+	case []byte:
+		return Binary(value), nil
+	case []Key:
+		return Array(value...), nil
+	case []interface{}:
+		array := make([]Key, 0, len(value))
+		for _, elem := range value {
+			k, err := Of(elem)
+			if err != nil {
+				return Key{}, err
+			}
+			array = append(array, k)
+		}
+		return Key{kind: KindArray, array: array}, nil
+	case int:
+		return Number(float64(value)), nil
+	case int8:
+		return Number(float64(value)), nil
+	case int16:
+		return Number(float64(value)), nil
+	case int32:
+		return Number(float64(value)), nil
+	case int64:
+		return Number(float64(value)), nil
+	case uint:
+		return Number(float64(value)), nil
+	case uint8:
+		return Number(float64(value)), nil
+	case uint16:
+		return Number(float64(value)), nil
+	case uint32:
+		return Number(float64(value)), nil
+	case uint64:
+		return Number(float64(value)), nil
+	case float32:
+		return ofFloat(float64(value))
+	case float64:
+		return ofFloat(value)
+	default:
+		return Key{}, fmt.Errorf("%w: unsupported type %T", ErrInvalidKey, value)
+	}
+}
+
+func ofFloat(n float64) (Key, error) {
+	if math.IsNaN(n) {
+		return Key{}, fmt.Errorf("%w: NaN", ErrInvalidKey)
+	}
+	return Number(n), nil
+}
+
+// Kind returns the type of k
+func (k Key) Kind() Kind {
+	return k.kind
+}
+
+// Value returns k as a Go value. Returns a float64 for numbers, time.Time for dates, string for strings, []byte for binary, and []Key for arrays.
+func (k Key) Value() interface{} {
+	switch k.kind {
+	case KindNumber:
+		return k.number
+	case KindDate:
+		return time.UnixMilli(int64(k.number))
+	case KindString:
+		return k.str
+	case KindBinary:
+		return append([]byte{}, k.binary...)
+	default:
+		return append([]Key{}, k.array...)
+	}
+}
+
+// Compare returns -1 if k is less than other, 1 if k is greater than other, or 0 if they are equal. Uses the same ordering as IndexedDB.
+func (k Key) Compare(other Key) int {
+	if k.kind != other.kind {
+		return compareInts(int(k.kind), int(other.kind))
+	}
+	switch k.kind {
+	case KindNumber, KindDate:
+		switch {
+		case k.number < other.number:
+			return -1
+		case k.number > other.number:
+			return 1
+		default:
+			return 0
+		}
+	case KindString:
+		return compareUTF16(k.str, other.str)
+	case KindBinary:
+		return bytes.Compare(k.binary, other.binary)
+	default:
+		for i := 0; i < len(k.array) && i < len(other.array); i++ {
+			if c := k.array[i].Compare(other.array[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(k.array), len(other.array))
+	}
+}
+
+// Equal returns true if k and other are the same key
+func (k Key) Equal(other Key) bool {
+	return k.Compare(other) == 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareUTF16 compares strings by their UTF-16 code units, like JavaScript does
+func compareUTF16(a, b string) int {
+	aRunes, bRunes := []rune(a), []rune(b)
+	for i := 0; i < len(aRunes) && i < len(bRunes); i++ {
+		if c := compareInts(utf16Order(aRunes[i]), utf16Order(bRunes[i])); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(aRunes), len(bRunes))
+}
+
+const (
+	surrogateMin = 0xD800
+	maxBMPRune   = 0xFFFF
+)
+
+// utf16Order maps a rune to its position when sorting by UTF-16 code units. Surrogate pairs sort between U+D7FF and U+E000.
+func utf16Order(r rune) int {
+	switch {
+	case r < surrogateMin:
+		return int(r)
+	case r > maxBMPRune:
+		return surrogateMin + int(r-maxBMPRune-1)
+	default:
+		return int(r) + utf8.MaxRune
+	}
+}
+
+// nextUTF16Rune returns the next rune in UTF-16 code unit order, or false if r is the last one
+func nextUTF16Rune(r rune) (rune, bool) {
+	switch r {
+	case surrogateMin - 1:
+		return maxBMPRune + 1, true
+	case utf8.MaxRune:
+		return 0xE000, true
+	case maxBMPRune:
+		return 0, false
+	default:
+		return r + 1, true
+	}
+}
+
+// successor returns the smallest key greater than k
+func (k Key) successor() Key {
+	switch k.kind {
+	case KindNumber:
+		if math.IsInf(k.number, 1) {
+			return Key{kind: KindDate, number: minDateMillis}
+		}
+		return Key{kind: KindNumber, number: math.Nextafter(k.number, math.Inf(1))}
+	case KindDate:
+		if k.number >= maxDateMillis {
+			return Key{kind: KindString}
+		}
+		return Key{kind: KindDate, number: math.Floor(k.number) + 1}
+	case KindString:
+		return Key{kind: KindString, str: k.str + "\x00"}
+	case KindBinary:
+		return Key{kind: KindBinary, binary: append(append([]byte{}, k.binary...), 0)}
+	default:
+		array := append([]Key{}, k.array...)
+		return Key{kind: KindArray, array: append(array, Number(math.Inf(-1)))}
+	}
+}
+
+// prefixEnd returns the smallest key greater than every key starting with the string, binary, or array key k.
+// Returns false if no such key exists with the same kind, meaning every greater key of the same kind starts with k.
+func (k Key) prefixEnd() (Key, bool) {
+	switch k.kind {
+	case KindString:
+		runes := []rune(k.str)
+		for i := len(runes) - 1; i >= 0; i-- {
+			if next, ok := nextUTF16Rune(runes[i]); ok {
+				runes[i] = next
+				return Key{kind: KindString, str: string(runes[:i+1])}, true
+			}
+		}
+	case KindBinary:
+		for i := len(k.binary) - 1; i >= 0; i-- {
+			if k.binary[i] < math.MaxUint8 {
+				binary := append([]byte{}, k.binary[:i+1]...)
+				binary[i]++
+				return Key{kind: KindBinary, binary: binary}, true
+			}
+		}
+	case KindArray:
+		if len(k.array) > 0 {
+			array := append([]Key{}, k.array...)
+			last := len(array) - 1
+			array[last] = array[last].successor()
+			return Key{kind: KindArray, array: array}, true
+		}
+	}
+	return Key{}, false
+}
+
+// String returns a human-readable representation of k
+func (k Key) String() string {
+	switch k.kind {
+	case KindNumber:
+		return formatNumber(k.number)
+	case KindDate:
+		return "Date(" + time.UnixMilli(int64(k.number)).UTC().Format(time.RFC3339Nano) + ")"
+	case KindString:
+		return strconv.Quote(k.str)
+	case KindBinary:
+		return "Binary(" + hex.EncodeToString(k.binary) + ")"
+	default:
+		elems := make([]string, 0, len(k.array))
+		for _, elem := range k.array {
+			elems = append(elems, elem.String())
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+}
+
+func formatNumber(n float64) string {
+	switch {
+	case math.IsInf(n, 1):
+		return "Infinity"
+	case math.IsInf(n, -1):
+		return "-Infinity"
+	default:
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	}
+}
+
+// jsonKey is the JSON encoding of a Key. Exactly one field is set.
+type jsonKey struct {
+	Number *json.RawMessage `json:"number,omitempty"`
+	Date   *float64         `json:"date,omitempty"`
+	String *string          `json:"string,omitempty"`
+	Binary *[]byte          `json:"binary,omitempty"`
+	Array  *[]Key           `json:"array,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. Keys are encoded as an object with a single field named after the key's Kind, like {"string":"a"}.
+// Dates are encoded as milliseconds since the Unix epoch, binary as base64, and infinite numbers as the strings "Infinity" and "-Infinity".
+func (k Key) MarshalJSON() ([]byte, error) {
+	var encoded jsonKey
+	switch k.kind {
+	case KindNumber:
+		number := json.RawMessage(formatNumber(k.number))
+		if math.IsInf(k.number, 0) {
+			number = json.RawMessage(strconv.Quote(string(number)))
+		}
+		encoded.Number = &number
+	case KindDate:
+		encoded.Date = &k.number
+	case KindString:
+		encoded.String = &k.str
+	case KindBinary:
+		binary := k.binary
+		if binary == nil {
+			binary = []byte{}
+		}
+		encoded.Binary = &binary
+	default:
+		array := k.array
+		if array == nil {
+			array = []Key{}
+		}
+		encoded.Array = &array
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (k *Key) UnmarshalJSON(b []byte) error {
+	var encoded jsonKey
+	err := json.Unmarshal(b, &encoded)
+	if err != nil {
+		return err
+	}
+	switch {
+	case encoded.Number != nil:
+		var number float64
+		var infinity string
+		if json.Unmarshal(*encoded.Number, &infinity) == nil {
+			switch infinity {
+			case "Infinity":
+				number = math.Inf(1)
+			case "-Infinity":
+				number = math.Inf(-1)
+			default:
+				return fmt.Errorf("%w: invalid number %q", ErrInvalidKey, infinity)
+			}
+		} else if err := json.Unmarshal(*encoded.Number, &number); err != nil {
+			return err
+		}
+		*k = Number(number)
+	case encoded.Date != nil:
+		*k = Key{kind: KindDate, number: math.Trunc(*encoded.Date)}
+	case encoded.String != nil:
+		*k = String(*encoded.String)
+	case encoded.Binary != nil:
+		*k = Key{kind: KindBinary, binary: *encoded.Binary}
+	case encoded.Array != nil:
+		*k = Key{kind: KindArray, array: *encoded.Array}
+	default:
+		return fmt.Errorf("%w: missing key type in JSON: %s", ErrInvalidKey, string(b))
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package idbkey
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sortedKeys are valid keys in ascending order
+var sortedKeys = []Key{
+	Number(math.Inf(-1)),
+	Number(-1),
+	Number(0),
+	Number(1.5),
+	Number(math.Inf(1)),
+	Date(time.UnixMilli(minDateMillis)),
+	Date(time.UnixMilli(0)),
+	Date(time.UnixMilli(1)),
+	Date(time.UnixMilli(maxDateMillis)),
+	String(""),
+	String("\x00"),
+	String("a"),
+	String("a\x00"),
+	String("b"),
+	String("\uD7FF"),
+	String("\U00010000"),
+	String("\U0010FFFF"),
+	String("\uE000"),
+	String("\uFFFF"),
+	Binary(nil),
+	Binary([]byte{0}),
+	Binary([]byte{0, 0}),
+	Binary([]byte{1}),
+	Binary([]byte{0xFF}),
+	Array(),
+	Array(Number(math.Inf(-1))),
+	Array(Number(1)),
+	Array(Number(1), Number(1)),
+	Array(String("a")),
+	Array(Array()),
+}
+
+func TestOf(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		value     interface{}
+		expectKey Key
+		expectErr error
+	}{
+		{name: "int", value: 1, expectKey: Number(1)},
+		{name: "uint8", value: uint8(1), expectKey: Number(1)},
+		{name: "float", value: 1.5, expectKey: Number(1.5)},
+		{name: "NaN", value: math.NaN(), expectErr: ErrInvalidKey},
+		{name: "string", value: "a", expectKey: String("a")},
+		{name: "time", value: time.UnixMilli(1000), expectKey: Date(time.UnixMilli(1000))},
+		{name: "bytes", value: []byte("a"), expectKey: Binary([]byte("a"))},
+		{name: "key", value: String("a"), expectKey: String("a")},
+		{name: "keys", value: []Key{String("a")}, expectKey: Array(String("a"))},
+		{name: "array", value: []interface{}{1, "a"}, expectKey: Array(Number(1), String("a"))},
+		{name: "invalid array element", value: []interface{}{true}, expectErr: ErrInvalidKey},
+		{name: "bool", value: true, expectErr: ErrInvalidKey},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			k, err := Of(tc.value)
+			if !errors.Is(err, tc.expectErr) {
+				t.Fatalf("Expected error %v, got: %v", tc.expectErr, err)
+			}
+			if !reflect.DeepEqual(tc.expectKey, k) {
+				t.Errorf("Expected key %s, got: %s", tc.expectKey, k)
+			}
+		})
+	}
+}
+
+func TestNumberNaN(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected NaN to panic")
+		}
+	}()
+	Number(math.NaN())
+}
+
+func TestKeyValue(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		key         Key
+		expectValue interface{}
+	}{
+		{key: Number(1), expectValue: 1.0},
+		{key: Date(time.UnixMilli(1000)), expectValue: time.UnixMilli(1000)},
+		{key: String("a"), expectValue: "a"},
+		{key: Binary([]byte("a")), expectValue: []byte("a")},
+		{key: Array(Number(1)), expectValue: []Key{Number(1)}},
+	} {
+		if value := tc.key.Value(); !reflect.DeepEqual(tc.expectValue, value) {
+			t.Errorf("Expected %#v, got: %#v", tc.expectValue, value)
+		}
+	}
+}
+
+func TestKeyCompare(t *testing.T) {
+	t.Parallel()
+	for i, a := range sortedKeys {
+		for j, b := range sortedKeys {
+			if c := a.Compare(b); c != compareInts(i, j) {
+				t.Errorf("Compare(%s, %s) = %d, expected %d", a, b, c, compareInts(i, j))
+			}
+			if equal := a.Equal(b); equal != (i == j) {
+				t.Errorf("Equal(%s, %s) = %t", a, b, equal)
+			}
+		}
+	}
+}
+
+func TestKeySuccessor(t *testing.T) {
+	t.Parallel()
+	for i, k := range sortedKeys {
+		successor := k.successor()
+		if successor.Compare(k) <= 0 {
+			t.Errorf("Successor %s is not greater than %s", successor, k)
+		}
+		if i+1 < len(sortedKeys) && successor.Compare(sortedKeys[i+1]) > 0 {
+			t.Errorf("Successor %s is greater than next key %s", successor, sortedKeys[i+1])
+		}
+	}
+}
+
+func TestKeyPrefixEnd(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name      string
+		prefix    Key
+		expectEnd Key
+		expectOK  bool
+	}{
+		{name: "string", prefix: String("foo/"), expectEnd: String("foo0"), expectOK: true},
+		{name: "string last BMP rune before surrogates", prefix: String("a\uD7FF"), expectEnd: String("a\U00010000"), expectOK: true},
+		{name: "string last astral rune", prefix: String("a\U0010FFFF"), expectEnd: String("a\uE000"), expectOK: true},
+		{name: "string carry", prefix: String("a\uFFFF"), expectEnd: String("b"), expectOK: true},
+		{name: "string max", prefix: String("\uFFFF\uFFFF")},
+		{name: "empty string", prefix: String("")},
+		{name: "binary", prefix: Binary([]byte{1, 2}), expectEnd: Binary([]byte{1, 3}), expectOK: true},
+		{name: "binary carry", prefix: Binary([]byte{1, 0xFF}), expectEnd: Binary([]byte{2}), expectOK: true},
+		{name: "binary max", prefix: Binary([]byte{0xFF})},
+		{name: "array", prefix: Array(String("a"), String("b")), expectEnd: Array(String("a"), String("b\x00")), expectOK: true},
+		{name: "empty array", prefix: Array()},
+		{name: "number", prefix: Number(1)},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			end, ok := tc.prefix.prefixEnd()
+			if ok != tc.expectOK {
+				t.Fatalf("Expected ok = %t, got %t", tc.expectOK, ok)
+			}
+			if ok && !reflect.DeepEqual(tc.expectEnd, end) {
+				t.Errorf("Expected end %s, got: %s", tc.expectEnd, end)
+			}
+		})
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		key          Key
+		expectString string
+	}{
+		{key: Number(1.5), expectString: "1.5"},
+		{key: Number(math.Inf(-1)), expectString: "-Infinity"},
+		{key: Date(time.UnixMilli(1000)), expectString: "Date(1970-01-01T00:00:01Z)"},
+		{key: String("a"), expectString: `"a"`},
+		{key: Binary([]byte{1, 0xAB}), expectString: "Binary(01ab)"},
+		{key: Array(Number(1), Array(String("a"))), expectString: `[1, ["a"]]`},
+	} {
+		if s := tc.key.String(); s != tc.expectString {
+			t.Errorf("Expected %q, got: %q", tc.expectString, s)
+		}
+	}
+}
+
+func TestKeyJSON(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		key        Key
+		expectJSON string
+	}{
+		{key: Number(1.5), expectJSON: `{"number":1.5}`},
+		{key: Number(math.Inf(1)), expectJSON: `{"number":"Infinity"}`},
+		{key: Number(math.Inf(-1)), expectJSON: `{"number":"-Infinity"}`},
+		{key: Date(time.UnixMilli(1000)), expectJSON: `{"date":1000}`},
+		{key: String(""), expectJSON: `{"string":""}`},
+		{key: Binary([]byte{1, 2}), expectJSON: `{"binary":"AQI="}`},
+		{key: Array(), expectJSON: `{"array":[]}`},
+		{key: Array(Number(1), String("a")), expectJSON: `{"array":[{"number":1},{"string":"a"}]}`},
+	} {
+		encoded, err := json.Marshal(tc.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tc.expectJSON {
+			t.Errorf("Expected %s, got: %s", tc.expectJSON, encoded)
+		}
+		var decoded Key
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Equal(tc.key) {
+			t.Errorf("Expected %s, got: %s", tc.key, decoded)
+		}
+	}
+
+	for _, invalidJSON := range []string{`{}`, `{"number":"NaN"}`, `{"number":true}`} {
+		var decoded Key
+		if err := json.Unmarshal([]byte(invalidJSON), &decoded); err == nil {
+			t.Errorf("Expected error decoding %s", invalidJSON)
+		}
+	}
+}
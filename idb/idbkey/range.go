@@ -0,0 +1,279 @@
+package idbkey
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrInvalidRange is returned when a range's lower bound is greater than its upper bound, or the range would otherwise be empty
+	ErrInvalidRange = errors.New("invalid key range")
+)
+
+// Range is a continuous interval of keys, with optional lower and upper bounds. The zero value is unbounded and contains every key.
+type Range struct {
+	lower, upper         Key
+	hasLower, hasUpper   bool
+	lowerOpen, upperOpen bool
+}
+
+// Bound returns a range with the specified lower and upper bounds.
+// The bounds can be open (that is, the bounds exclude the endpoint values) or closed (that is, the bounds include the endpoint values).
+func Bound(lower, upper Key, lowerOpen, upperOpen bool) (Range, error) {
+	r := Range{
+		lower:     lower,
+		upper:     upper,
+		hasLower:  true,
+		hasUpper:  true,
+		lowerOpen: lowerOpen,
+		upperOpen: upperOpen,
+	}
+	if r.isEmpty() {
+		return Range{}, fmt.Errorf("%w: lower bound %s is not less than upper bound %s", ErrInvalidRange, lower, upper)
+	}
+	return r, nil
+}
+
+// LowerBound returns a range with only a lower bound
+func LowerBound(lower Key, open bool) Range {
+	return Range{lower: lower, hasLower: true, lowerOpen: open}
+}
+
+// UpperBound returns a range with only an upper bound
+func UpperBound(upper Key, open bool) Range {
+	return Range{upper: upper, hasUpper: true, upperOpen: open}
+}
+
+// Only returns a range containing a single key
+func Only(k Key) Range {
+	return Range{lower: k, upper: k, hasLower: true, hasUpper: true}
+}
+
+// Prefix returns a range containing every key starting with prefix, which must be a string, binary, or array key.
+// Strings match by UTF-16 code units, binary keys by bytes, and array keys by their leading elements.
+func Prefix(prefix Key) (Range, error) {
+	switch prefix.kind {
+	case KindString, KindBinary, KindArray:
+	default:
+		return Range{}, fmt.Errorf("%w: prefix must be a string, binary, or array key, got %s", ErrInvalidKey, prefix.kind)
+	}
+	r := LowerBound(prefix, false)
+	upper, ok := prefix.prefixEnd()
+	switch {
+	case ok:
+		r.upper, r.hasUpper, r.upperOpen = upper, true, true
+	case prefix.kind != KindArray:
+		// every greater key of the same kind starts with prefix, so stop before the next kind
+		r.upper, r.hasUpper, r.upperOpen = Key{kind: prefix.kind + 1}, true, true
+	}
+	return r, nil
+}
+
+// Lower returns the lower bound of r. Returns false if r has no lower bound.
+func (r Range) Lower() (Key, bool) {
+	return r.lower, r.hasLower
+}
+
+// Upper returns the upper bound of r. Returns false if r has no upper bound.
+func (r Range) Upper() (Key, bool) {
+	return r.upper, r.hasUpper
+}
+
+// LowerOpen returns false if the lower bound is included in r. Like IndexedDB, returns true if r has no lower bound.
+func (r Range) LowerOpen() bool {
+	return !r.hasLower || r.lowerOpen
+}
+
+// UpperOpen returns false if the upper bound is included in r. Like IndexedDB, returns true if r has no upper bound.
+func (r Range) UpperOpen() bool {
+	return !r.hasUpper || r.upperOpen
+}
+
+// Includes returns true if k is inside r
+func (r Range) Includes(k Key) bool {
+	if r.hasLower {
+		c := k.Compare(r.lower)
+		if c < 0 || (c == 0 && r.lowerOpen) {
+			return false
+		}
+	}
+	if r.hasUpper {
+		c := k.Compare(r.upper)
+		if c > 0 || (c == 0 && r.upperOpen) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if r and other contain exactly the same keys
+func (r Range) Equal(other Range) bool {
+	return compareLower(r, other) == 0 && compareUpper(r, other) == 0
+}
+
+// Intersect returns the range of keys inside both r and other. Returns false if they do not overlap.
+func (r Range) Intersect(other Range) (Range, bool) {
+	intersection := r
+	if compareLower(other, r) > 0 {
+		intersection.lower, intersection.hasLower, intersection.lowerOpen = other.lower, other.hasLower, other.lowerOpen
+	}
+	if compareUpper(other, r) < 0 {
+		intersection.upper, intersection.hasUpper, intersection.upperOpen = other.upper, other.hasUpper, other.upperOpen
+	}
+	if intersection.isEmpty() {
+		return Range{}, false
+	}
+	return intersection, true
+}
+
+// Union returns the ranges containing every key inside either r or other. Returns 1 range if they overlap or touch, otherwise returns both sorted by their lower bounds.
+func (r Range) Union(other Range) []Range {
+	return Union(r, other)
+}
+
+// Union returns the smallest list of ranges containing every key inside ranges. The result does not overlap and is sorted by lower bounds.
+func Union(ranges ...Range) []Range {
+	sorted := append([]Range{}, ranges...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareLower(sorted[i], sorted[j]) < 0
+	})
+
+	var merged []Range
+	for _, r := range sorted {
+		last := len(merged) - 1
+		if last < 0 || hasGap(merged[last], r) {
+			merged = append(merged, r)
+			continue
+		}
+		if compareUpper(r, merged[last]) > 0 {
+			merged[last].upper, merged[last].hasUpper, merged[last].upperOpen = r.upper, r.hasUpper, r.upperOpen
+		}
+	}
+	return merged
+}
+
+// compareLower compares lower bounds. Unbounded is the smallest, and an open bound is greater than a closed bound on the same key.
+func compareLower(a, b Range) int {
+	switch {
+	case !a.hasLower && !b.hasLower:
+		return 0
+	case !a.hasLower:
+		return -1
+	case !b.hasLower:
+		return 1
+	}
+	if c := a.lower.Compare(b.lower); c != 0 {
+		return c
+	}
+	return compareBools(a.lowerOpen, b.lowerOpen)
+}
+
+// compareUpper compares upper bounds. Unbounded is the largest, and an open bound is less than a closed bound on the same key.
+func compareUpper(a, b Range) int {
+	switch {
+	case !a.hasUpper && !b.hasUpper:
+		return 0
+	case !a.hasUpper:
+		return 1
+	case !b.hasUpper:
+		return -1
+	}
+	if c := a.upper.Compare(b.upper); c != 0 {
+		return c
+	}
+	return -compareBools(a.upperOpen, b.upperOpen)
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// isEmpty returns true if no key can be inside r
+func (r Range) isEmpty() bool {
+	if !r.hasLower || !r.hasUpper {
+		return false
+	}
+	c := r.lower.Compare(r.upper)
+	return c > 0 || (c == 0 && (r.lowerOpen || r.upperOpen))
+}
+
+// hasGap returns true if some key lies between a's upper bound and b's lower bound
+func hasGap(a, b Range) bool {
+	if !a.hasUpper || !b.hasLower {
+		return false
+	}
+	c := a.upper.Compare(b.lower)
+	return c < 0 || (c == 0 && a.upperOpen && b.lowerOpen)
+}
+
+// String returns a human-readable representation of r in interval notation, like [1, 5)
+func (r Range) String() string {
+	lower, upper := "-Infinity", "+Infinity"
+	lowerBracket, upperBracket := "(", ")"
+	if r.hasLower {
+		lower = r.lower.String()
+		if !r.lowerOpen {
+			lowerBracket = "["
+		}
+	}
+	if r.hasUpper {
+		upper = r.upper.String()
+		if !r.upperOpen {
+			upperBracket = "]"
+		}
+	}
+	return lowerBracket + lower + ", " + upper + upperBracket
+}
+
+// jsonRange is the JSON encoding of a Range. Missing bounds are unbounded.
+type jsonRange struct {
+	Lower     *Key `json:"lower,omitempty"`
+	Upper     *Key `json:"upper,omitempty"`
+	LowerOpen bool `json:"lowerOpen,omitempty"`
+	UpperOpen bool `json:"upperOpen,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (r Range) MarshalJSON() ([]byte, error) {
+	var encoded jsonRange
+	if r.hasLower {
+		encoded.Lower, encoded.LowerOpen = &r.lower, r.lowerOpen
+	}
+	if r.hasUpper {
+		encoded.Upper, encoded.UpperOpen = &r.upper, r.upperOpen
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *Range) UnmarshalJSON(b []byte) error {
+	var encoded jsonRange
+	err := json.Unmarshal(b, &encoded)
+	if err != nil {
+		return err
+	}
+	decoded := Range{
+		hasLower: encoded.Lower != nil,
+		hasUpper: encoded.Upper != nil,
+	}
+	if decoded.hasLower {
+		decoded.lower, decoded.lowerOpen = *encoded.Lower, encoded.LowerOpen
+	}
+	if decoded.hasUpper {
+		decoded.upper, decoded.upperOpen = *encoded.Upper, encoded.UpperOpen
+	}
+	if decoded.isEmpty() {
+		return fmt.Errorf("%w: %s", ErrInvalidRange, decoded)
+	}
+	*r = decoded
+	return nil
+}
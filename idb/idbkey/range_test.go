@@ -0,0 +1,304 @@
+package idbkey
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustBound(tb testing.TB, lower, upper Key, lowerOpen, upperOpen bool) Range {
+	tb.Helper()
+	r, err := Bound(lower, upper, lowerOpen, upperOpen)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return r
+}
+
+func TestBound(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name                 string
+		lower, upper         Key
+		lowerOpen, upperOpen bool
+		expectErr            error
+	}{
+		{name: "closed", lower: Number(0), upper: Number(1)},
+		{name: "same key closed", lower: Number(0), upper: Number(0)},
+		{name: "same key open", lower: Number(0), upper: Number(0), lowerOpen: true, expectErr: ErrInvalidRange},
+		{name: "lower greater than upper", lower: Number(1), upper: Number(0), expectErr: ErrInvalidRange},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Bound(tc.lower, tc.upper, tc.lowerOpen, tc.upperOpen)
+			if !errors.Is(err, tc.expectErr) {
+				t.Errorf("Expected error %v, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestRangeBounds(t *testing.T) {
+	t.Parallel()
+	r := LowerBound(Number(1), false)
+	if lower, ok := r.Lower(); !ok || !lower.Equal(Number(1)) {
+		t.Errorf("Expected lower bound 1, got: %s %t", lower, ok)
+	}
+	if _, ok := r.Upper(); ok {
+		t.Error("Expected no upper bound")
+	}
+	if r.LowerOpen() {
+		t.Error("Expected closed lower bound")
+	}
+	if !r.UpperOpen() {
+		t.Error("Expected open upper bound when unbounded")
+	}
+}
+
+func TestRangeIncludes(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name           string
+		r              Range
+		key            Key
+		expectIncludes bool
+	}{
+		{name: "closed lower bound", r: mustBound(t, Number(0), Number(10), false, true), key: Number(0), expectIncludes: true},
+		{name: "open upper bound", r: mustBound(t, Number(0), Number(10), false, true), key: Number(10), expectIncludes: false},
+		{name: "inside bounds", r: mustBound(t, Number(0), Number(10), true, true), key: Number(5), expectIncludes: true},
+		{name: "open lower bound", r: LowerBound(Number(0), true), key: Number(0), expectIncludes: false},
+		{name: "lower bound other kind", r: LowerBound(Number(0), true), key: String("a"), expectIncludes: true},
+		{name: "closed upper bound", r: UpperBound(Number(0), false), key: Number(0), expectIncludes: true},
+		{name: "only", r: Only(String("a")), key: String("a"), expectIncludes: true},
+		{name: "not only", r: Only(String("a")), key: String("b"), expectIncludes: false},
+		{name: "unbounded", r: Range{}, key: Array(), expectIncludes: true},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if includes := tc.r.Includes(tc.key); includes != tc.expectIncludes {
+				t.Errorf("Expected %s includes %s = %t", tc.r, tc.key, tc.expectIncludes)
+			}
+		})
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name           string
+		prefix         Key
+		key            Key
+		expectIncludes bool
+	}{
+		{name: "string prefix", prefix: String("foo/"), key: String("foo/"), expectIncludes: true},
+		{name: "string with prefix", prefix: String("foo/"), key: String("foo/bar"), expectIncludes: true},
+		{name: "string with max suffix", prefix: String("foo/"), key: String("foo/\uFFFF\uFFFF"), expectIncludes: true},
+		{name: "string with astral suffix", prefix: String("foo/"), key: String("foo/\U0001F600"), expectIncludes: true},
+		{name: "string without prefix", prefix: String("foo/"), key: String("foo0"), expectIncludes: false},
+		{name: "string smaller than prefix", prefix: String("foo/"), key: String("foo"), expectIncludes: false},
+		{name: "string before astral rune", prefix: String("a\uD7FF"), key: String("a\U00010000"), expectIncludes: false},
+		{name: "string with max prefix", prefix: String("\uFFFF"), key: String("\uFFFF\uFFFF"), expectIncludes: true},
+		{name: "string max prefix excludes other types", prefix: String("\uFFFF"), key: Binary(nil), expectIncludes: false},
+		{name: "empty string prefix", prefix: String(""), key: String("a"), expectIncludes: true},
+		{name: "empty string prefix excludes numbers", prefix: String(""), key: Number(1), expectIncludes: false},
+		{name: "binary with prefix", prefix: Binary([]byte{1, 0xFF}), key: Binary([]byte{1, 0xFF, 0xFF}), expectIncludes: true},
+		{name: "binary without prefix", prefix: Binary([]byte{1, 0xFF}), key: Binary([]byte{2}), expectIncludes: false},
+		{name: "array with prefix", prefix: Array(String("a")), key: Array(String("a"), Number(1)), expectIncludes: true},
+		{name: "array with array suffix", prefix: Array(String("a")), key: Array(String("a"), Array()), expectIncludes: true},
+		{name: "array without prefix", prefix: Array(String("a")), key: Array(String("a\x00")), expectIncludes: false},
+		{name: "empty array prefix", prefix: Array(), key: Array(Number(1)), expectIncludes: true},
+		{name: "empty array prefix excludes other types", prefix: Array(), key: String("a"), expectIncludes: false},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := Prefix(tc.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if includes := r.Includes(tc.key); includes != tc.expectIncludes {
+				t.Errorf("Expected %s includes %s = %t", r, tc.key, tc.expectIncludes)
+			}
+		})
+	}
+
+	_, err := Prefix(Number(1))
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected invalid key error, got: %v", err)
+	}
+}
+
+func TestRangeIntersect(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name         string
+		a, b         Range
+		expectOK     bool
+		expectResult Range
+	}{
+		{
+			name:         "overlap",
+			a:            mustBound(t, Number(0), Number(10), false, false),
+			b:            mustBound(t, Number(5), Number(15), true, false),
+			expectOK:     true,
+			expectResult: mustBound(t, Number(5), Number(10), true, false),
+		},
+		{
+			name:         "unbounded",
+			a:            LowerBound(Number(0), false),
+			b:            UpperBound(Number(10), true),
+			expectOK:     true,
+			expectResult: mustBound(t, Number(0), Number(10), false, true),
+		},
+		{
+			name:         "same key with open bound",
+			a:            LowerBound(Number(0), true),
+			b:            LowerBound(Number(0), false),
+			expectOK:     true,
+			expectResult: LowerBound(Number(0), true),
+		},
+		{
+			name:         "touching",
+			a:            UpperBound(Number(5), false),
+			b:            LowerBound(Number(5), false),
+			expectOK:     true,
+			expectResult: Only(Number(5)),
+		},
+		{
+			name: "touching open",
+			a:    UpperBound(Number(5), true),
+			b:    LowerBound(Number(5), false),
+		},
+		{
+			name: "disjoint",
+			a:    Only(String("a")),
+			b:    Only(String("b")),
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			result, ok := tc.a.Intersect(tc.b)
+			if ok != tc.expectOK {
+				t.Fatalf("Expected ok = %t, got: %t", tc.expectOK, ok)
+			}
+			if ok && !result.Equal(tc.expectResult) {
+				t.Errorf("Expected %s, got: %s", tc.expectResult, result)
+			}
+		})
+	}
+}
+
+func TestUnion(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name         string
+		ranges       []Range
+		expectRanges []Range
+	}{
+		{
+			name: "overlap",
+			ranges: []Range{
+				mustBound(t, Number(5), Number(15), true, false),
+				mustBound(t, Number(0), Number(10), false, true),
+			},
+			expectRanges: []Range{mustBound(t, Number(0), Number(15), false, false)},
+		},
+		{
+			name: "touching",
+			ranges: []Range{
+				mustBound(t, Number(0), Number(5), false, true),
+				mustBound(t, Number(5), Number(10), false, true),
+			},
+			expectRanges: []Range{mustBound(t, Number(0), Number(10), false, true)},
+		},
+		{
+			name: "disjoint",
+			ranges: []Range{
+				Only(String("b")),
+				mustBound(t, Number(0), Number(5), false, true),
+				mustBound(t, Number(5), Number(10), true, true),
+			},
+			expectRanges: []Range{
+				mustBound(t, Number(0), Number(5), false, true),
+				mustBound(t, Number(5), Number(10), true, true),
+				Only(String("b")),
+			},
+		},
+		{
+			name: "unbounded",
+			ranges: []Range{
+				UpperBound(Number(10), false),
+				LowerBound(Number(5), false),
+			},
+			expectRanges: []Range{{}},
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			result := Union(tc.ranges...)
+			if len(result) != len(tc.expectRanges) {
+				t.Fatalf("Expected %v, got: %v", tc.expectRanges, result)
+			}
+			for i := range result {
+				if !result[i].Equal(tc.expectRanges[i]) {
+					t.Errorf("Expected %v, got: %v", tc.expectRanges, result)
+				}
+			}
+		})
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		r            Range
+		expectString string
+	}{
+		{r: mustBound(t, Number(1), Number(5), false, true), expectString: "[1, 5)"},
+		{r: LowerBound(String("a"), true), expectString: `("a", +Infinity)`},
+		{r: UpperBound(String("a"), false), expectString: `(-Infinity, "a"]`},
+		{r: Range{}, expectString: "(-Infinity, +Infinity)"},
+	} {
+		if s := tc.r.String(); s != tc.expectString {
+			t.Errorf("Expected %q, got: %q", tc.expectString, s)
+		}
+	}
+}
+
+func TestRangeJSON(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		r          Range
+		expectJSON string
+	}{
+		{r: mustBound(t, Number(1), Number(5), false, true), expectJSON: `{"lower":{"number":1},"upper":{"number":5},"upperOpen":true}`},
+		{r: LowerBound(String("a"), true), expectJSON: `{"lower":{"string":"a"},"lowerOpen":true}`},
+		{r: Range{}, expectJSON: `{}`},
+	} {
+		encoded, err := json.Marshal(tc.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tc.expectJSON {
+			t.Errorf("Expected %s, got: %s", tc.expectJSON, encoded)
+		}
+		var decoded Range
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tc.r, decoded) {
+			t.Errorf("Expected %s, got: %s", tc.r, decoded)
+		}
+	}
+
+	var decoded Range
+	err := json.Unmarshal([]byte(`{"lower":{"number":5},"upper":{"number":1}}`), &decoded)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected invalid range error, got: %v", err)
+	}
+}
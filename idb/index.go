@@ -44,6 +44,15 @@ func (i *Index) Name() (string, error) {
 	return name.String()
 }
 
+// SetName renames this index. Only allowed in a version change transaction, like inside an Upgrader.
+func (i *Index) SetName(name string) error {
+	if err := i.base.txn.requireVersionChange(); err != nil {
+		return err
+	}
+	err := i.base.jsObjectStore.Set("name", name)
+	return tryAsDOMException(err)
+}
+
 // KeyPath returns the key path of this index. If js.Null(), this index is not auto-populated.
 func (i *Index) KeyPath() (js.Value, error) {
 	value, err := i.base.jsObjectStore.Get("keyPath")
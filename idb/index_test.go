@@ -50,6 +50,42 @@ func TestIndexName(t *testing.T) {
 	assert.Equal(t, "myindex", name)
 }
 
+func TestIndexSetName(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", js.ValueOf("primary"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	db = upgradeTestDB(t, db, func(db *Database, oldVersion, newVersion uint) error {
+		txn, err := db.UpgradeTransaction()
+		if err != nil {
+			return err
+		}
+		store, err := txn.ObjectStore("mystore")
+		if err != nil {
+			return err
+		}
+		index, err := store.Index("myindex")
+		if err != nil {
+			return err
+		}
+		return index.SetName("renamed")
+	})
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	indexNames, err := store.IndexNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"renamed"}, indexNames)
+
+	index, err := store.Index("renamed")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, index.SetName("other"), errNotVersionChange)
+}
+
 func TestIndexKeyPath(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {
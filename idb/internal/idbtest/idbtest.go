@@ -0,0 +1,51 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package idbtest creates temporary databases for tests
+package idbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+// DBPrefix prefixes every test database name. It matches the idb package's testDBPrefix, so leftover databases from any package's tests are deleted by its cleanup.
+const DBPrefix = "go-indexeddb-test-"
+
+// DBName returns a new database name for tb, and deletes the database when tb completes.
+// Close any connections to the database in a later tb.Cleanup, so they close before it's deleted.
+func DBName(tb testing.TB) string {
+	tb.Helper()
+	n, err := rand.Int(rand.Reader, big.NewInt(1000))
+	assert.NoError(tb, err)
+	name := fmt.Sprintf("%s%s/%d", DBPrefix, tb.Name(), n.Int64())
+	tb.Cleanup(func() {
+		req, err := idb.Global().DeleteDatabase(name)
+		assert.NoError(tb, err)
+		assert.NoError(tb, req.Await(context.Background()))
+	})
+	return name
+}
+
+// DB opens a new database at version 1 with upgrader, then closes and deletes it when tb completes
+func DB(tb testing.TB, upgrader idb.Upgrader) *idb.Database {
+	tb.Helper()
+	req, err := idb.Global().Open(context.Background(), DBName(tb), 1, upgrader)
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	db, err := req.Await(context.Background())
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, db.Close())
+	})
+	return db
+}
@@ -0,0 +1,105 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package promise converts between JavaScript Promises and Go
+package promise
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsPromise safejs.Value
+)
+
+func init() {
+	var err error
+	jsPromise, err = safejs.Global().Get("Promise")
+	if err != nil {
+		panic(err)
+	}
+}
+
+type result struct {
+	value safejs.Value
+	err   error
+}
+
+// Await waits for promise to settle, then returns its fulfilled value. If promise rejects, returns a js.Error with the rejection reason.
+// Returns ctx.Err() if ctx is canceled first.
+func Await(ctx context.Context, promise safejs.Value) (safejs.Value, error) {
+	results := make(chan result, 1)
+	settled := make(chan struct{})
+	settle := func(r result) {
+		results <- r
+		close(settled)
+	}
+	onFulfilled, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		settle(result{value: firstArg(args)})
+		return nil
+	})
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	onRejected, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		settle(result{err: js.Error{Value: safejs.Unsafe(firstArg(args))}})
+		return nil
+	})
+	if err != nil {
+		onFulfilled.Release()
+		return safejs.Value{}, err
+	}
+	go func() {
+		<-settled
+		onFulfilled.Release()
+		onRejected.Release()
+	}()
+	_, err = promise.Call("then", onFulfilled, onRejected)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+
+	select {
+	case r := <-results:
+		return r.value, r.err
+	case <-ctx.Done():
+		return safejs.Value{}, ctx.Err()
+	}
+}
+
+func firstArg(args []safejs.Value) safejs.Value {
+	if len(args) == 0 {
+		return safejs.Safe(js.Undefined())
+	}
+	return args[0]
+}
+
+// New returns a new pending Promise, along with resolve and reject funcs to settle it.
+// Only the first call to resolve or reject has any effect.
+func New() (promise safejs.Value, resolve, reject func(value interface{}) error, err error) {
+	var jsResolve, jsReject safejs.Value
+	executor, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		jsResolve, jsReject = args[0], args[1]
+		return nil
+	})
+	if err != nil {
+		return safejs.Value{}, nil, nil, err
+	}
+	defer executor.Release() // the executor runs synchronously inside the Promise constructor
+	promise, err = jsPromise.New(executor)
+	if err != nil {
+		return safejs.Value{}, nil, nil, err
+	}
+	resolve = func(value interface{}) error {
+		_, err := jsResolve.Invoke(value)
+		return err
+	}
+	reject = func(value interface{}) error {
+		_, err := jsReject.Invoke(value)
+		return err
+	}
+	return promise, resolve, reject, nil
+}
@@ -0,0 +1,49 @@
+//go:build js && wasm
+// +build js,wasm
+
+package promise
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestAwaitResolve(t *testing.T) {
+	t.Parallel()
+	p, resolve, _, err := New()
+	assert.NoError(t, err)
+	assert.NoError(t, resolve("value"))
+
+	value, err := Await(context.Background(), p)
+	assert.NoError(t, err)
+	str, err := value.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "value", str)
+}
+
+func TestAwaitReject(t *testing.T) {
+	t.Parallel()
+	p, _, reject, err := New()
+	assert.NoError(t, err)
+	assert.NoError(t, reject("reason"))
+
+	_, err = Await(context.Background(), p)
+	var jsErr js.Error
+	assert.Equal(t, true, errors.As(err, &jsErr))
+	assert.Equal(t, "reason", jsErr.Value.String())
+}
+
+func TestAwaitCanceled(t *testing.T) {
+	t.Parallel()
+	p, _, _, err := New()
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = Await(ctx, p)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,35 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package txnutil runs functions in transactions for the packages wrapping object stores
+package txnutil
+
+import (
+	"context"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+)
+
+// Run runs fn in a new transaction on storeNames, then waits for it to complete. Aborts the transaction if fn returns an error, and returns that error.
+func Run(ctx context.Context, db *idb.Database, mode idb.TransactionMode, storeNames []string, fn func(*idb.Transaction) error) error {
+	txn, err := db.Transaction(mode, storeNames[0], storeNames[1:]...)
+	if err != nil {
+		return err
+	}
+	if err := fn(txn); err != nil {
+		_ = txn.Abort()
+		return err
+	}
+	return txn.Await(ctx)
+}
+
+// RunStore is the same as Run on a single object store, and passes fn that store
+func RunStore(ctx context.Context, db *idb.Database, mode idb.TransactionMode, storeName string, fn func(*idb.ObjectStore) error) error {
+	return Run(ctx, db, mode, []string{storeName}, func(txn *idb.Transaction) error {
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		return fn(store)
+	})
+}
@@ -0,0 +1,242 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"errors"
+	"math"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsArray       safejs.Value
+	jsArrayBuffer safejs.Value
+	jsDate        safejs.Value
+	jsUint8Array  safejs.Value
+)
+
+func init() {
+	var err error
+	jsArray, err = safejs.Global().Get("Array")
+	if err != nil {
+		panic(err)
+	}
+	jsArrayBuffer, err = safejs.Global().Get("ArrayBuffer")
+	if err != nil {
+		panic(err)
+	}
+	jsDate, err = safejs.Global().Get("Date")
+	if err != nil {
+		panic(err)
+	}
+	jsUint8Array, err = safejs.Global().Get("Uint8Array")
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newDataError(message string) DOMException {
+	return DOMException{
+		name:    "DataError",
+		message: message,
+	}
+}
+
+// tryAsDataError converts invalid key and key range errors from idbkey into DataErrors, the same as IndexedDB would return
+func tryAsDataError(err error) error {
+	if errors.Is(err, idbkey.ErrInvalidKey) || errors.Is(err, idbkey.ErrInvalidRange) {
+		return newDataError(err.Error())
+	}
+	return err
+}
+
+// parseKey converts a Go value into a key. Supports the same values as idbkey.Of, plus js.Value keys, including inside []interface{} arrays.
+func parseKey(value interface{}) (idbkey.Key, error) {
+	switch value := value.(type) {
+	case js.Value:
+		return parseJSKey(safejs.Safe(value))
+	case safejs.Value:
+		return parseJSKey(value)
+	case []interface{}:
+		array := make([]idbkey.Key, 0, len(value))
+		for _, elem := range value {
+			k, err := parseKey(elem)
+			if err != nil {
+				return idbkey.Key{}, err
+			}
+			array = append(array, k)
+		}
+		return idbkey.Array(array...), nil
+	default:
+		k, err := idbkey.Of(value)
+		return k, tryAsDataError(err)
+	}
+}
+
+// parseJSKey converts a JS value into a key, returning a DataError if it is not a valid key
+func parseJSKey(value safejs.Value) (idbkey.Key, error) {
+	switch value.Type() {
+	case safejs.TypeNumber:
+		number, err := value.Float()
+		if err != nil {
+			return idbkey.Key{}, err
+		}
+		if math.IsNaN(number) {
+			return idbkey.Key{}, newDataError("NaN is not a valid key")
+		}
+		return idbkey.Number(number), nil
+	case safejs.TypeString:
+		str, err := value.String()
+		if err != nil {
+			return idbkey.Key{}, err
+		}
+		return idbkey.String(str), nil
+	case safejs.TypeObject:
+		return parseJSObjectKey(value)
+	default:
+		return idbkey.Key{}, newDataError("Invalid key type: " + value.Type().String())
+	}
+}
+
+func parseJSObjectKey(value safejs.Value) (idbkey.Key, error) {
+	isDate, err := value.InstanceOf(jsDate)
+	if err != nil {
+		return idbkey.Key{}, err
+	}
+	if isDate {
+		millis, err := value.Call("getTime")
+		if err != nil {
+			return idbkey.Key{}, err
+		}
+		number, err := millis.Float()
+		if err != nil {
+			return idbkey.Key{}, err
+		}
+		if math.IsNaN(number) {
+			return idbkey.Key{}, newDataError("Invalid Date is not a valid key")
+		}
+		return idbkey.Date(time.UnixMilli(int64(number))), nil
+	}
+
+	isArray, err := jsArray.Call("isArray", value)
+	if err != nil {
+		return idbkey.Key{}, err
+	}
+	if truthy, err := isArray.Truthy(); err != nil {
+		return idbkey.Key{}, err
+	} else if truthy {
+		var array []idbkey.Key
+		err := iterArray(value, func(i int, elem safejs.Value) (bool, error) {
+			k, err := parseJSKey(elem)
+			array = append(array, k)
+			return err == nil, err
+		})
+		return idbkey.Array(array...), err
+	}
+
+	binary, isBinary, err := bytesFromJSBuffer(value)
+	if err != nil {
+		return idbkey.Key{}, err
+	}
+	if !isBinary {
+		return idbkey.Key{}, newDataError("Invalid key object")
+	}
+	return idbkey.Binary(binary), nil
+}
+
+// bytesFromJSBuffer copies the contents of an ArrayBuffer or ArrayBuffer view (like a Uint8Array or DataView) into a []byte.
+// Returns false if value is not a buffer.
+func bytesFromJSBuffer(value safejs.Value) ([]byte, bool, error) {
+	array, isBuffer, err := uint8ArrayView(value)
+	if err != nil || !isBuffer {
+		return nil, false, err
+	}
+	length, err := array.Length()
+	if err != nil {
+		return nil, false, err
+	}
+	buf := make([]byte, length)
+	_, err = safejs.CopyBytesToGo(buf, array)
+	return buf, true, err
+}
+
+// uint8ArrayView returns a Uint8Array sharing the memory of the given ArrayBuffer or ArrayBuffer view.
+// Returns false if value is not a buffer.
+func uint8ArrayView(value safejs.Value) (safejs.Value, bool, error) {
+	isBuffer, err := value.InstanceOf(jsArrayBuffer)
+	if err != nil {
+		return safejs.Value{}, false, err
+	}
+	if isBuffer {
+		array, err := jsUint8Array.New(value)
+		return array, err == nil, err
+	}
+	isView, err := jsArrayBuffer.Call("isView", value)
+	if err != nil {
+		return safejs.Value{}, false, err
+	}
+	if truthy, err := isView.Truthy(); err != nil || !truthy {
+		return safejs.Value{}, false, err
+	}
+	props, err := jsGetProperties(value, "buffer", "byteOffset", "byteLength")
+	if err != nil {
+		return safejs.Value{}, false, err
+	}
+	array, err := jsUint8Array.New(props[0], props[1], props[2])
+	return array, err == nil, err
+}
+
+func jsGetProperties(value safejs.Value, names ...string) ([]safejs.Value, error) {
+	values := make([]safejs.Value, 0, len(names))
+	for _, name := range names {
+		property, err := value.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, property)
+	}
+	return values, nil
+}
+
+// ParseKey converts a JS key, like a cursor's key, into an idbkey.Key. Returns a DataError if value is not a valid key.
+func ParseKey(value js.Value) (idbkey.Key, error) {
+	return parseJSKey(safejs.Safe(value))
+}
+
+// KeyValue converts k into a JS key, for use with methods like ObjectStore.Get
+func KeyValue(k idbkey.Key) (js.Value, error) {
+	value, err := jsKey(k)
+	return safejs.Unsafe(value), err
+}
+
+// jsKey converts k into a JS key
+func jsKey(k idbkey.Key) (safejs.Value, error) {
+	switch value := k.Value().(type) {
+	case time.Time:
+		return jsDate.New(value.UnixMilli())
+	case []byte:
+		array, err := jsUint8Array.New(len(value))
+		if err != nil {
+			return safejs.Value{}, err
+		}
+		_, err = safejs.CopyBytesToJS(array, value)
+		return array, err
+	case []idbkey.Key:
+		elems := make([]interface{}, 0, len(value))
+		for _, elem := range value {
+			jsElem, err := jsKey(elem)
+			if err != nil {
+				return safejs.Value{}, err
+			}
+			elems = append(elems, jsElem)
+		}
+		return jsArray.Call("of", elems...)
+	default:
+		return safejs.ValueOf(value)
+	}
+}
@@ -4,8 +4,11 @@
 package idb
 
 import (
+	"math"
+	"sync"
 	"syscall/js"
 
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
 	"github.com/hack-pad/safejs"
 )
 
@@ -22,86 +25,203 @@ func init() {
 }
 
 // KeyRange represents a continuous interval over some data type that is used for keys. Records can be retrieved from ObjectStore and Index objects using keys or a range of keys.
+//
+// KeyRange is backed by a Go-native idbkey.Range, so inspecting it does not call into JavaScript. The JavaScript IDBKeyRange is created the first time the KeyRange is used in a request.
 type KeyRange struct {
+	keyRange idbkey.Range
+
+	jsOnce     sync.Once
 	jsKeyRange safejs.Value
+	jsErr      error
 }
 
-func wrapKeyRange(jsKeyRange safejs.Value) *KeyRange {
-	return &KeyRange{jsKeyRange}
+// NewKeyRange creates a new key range from the given Go-native range
+func NewKeyRange(keyRange idbkey.Range) *KeyRange {
+	return &KeyRange{keyRange: keyRange}
 }
 
 // NewKeyRangeBound creates a new key range with the specified upper and lower bounds.
 // The bounds can be open (that is, the bounds exclude the endpoint values) or closed (that is, the bounds include the endpoint values).
 func NewKeyRangeBound(lower, upper js.Value, lowerOpen, upperOpen bool) (*KeyRange, error) {
-	keyRange, err := jsIDBKeyRange.Call("bound", lower, upper, lowerOpen, upperOpen)
+	lowerKey, err := parseJSKey(safejs.Safe(lower))
+	if err != nil {
+		return nil, err
+	}
+	upperKey, err := parseJSKey(safejs.Safe(upper))
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, err
 	}
-	return wrapKeyRange(keyRange), nil
+	keyRange, err := idbkey.Bound(lowerKey, upperKey, lowerOpen, upperOpen)
+	if err != nil {
+		return nil, tryAsDataError(err)
+	}
+	return NewKeyRange(keyRange), nil
 }
 
 // NewKeyRangeLowerBound creates a new key range with only a lower bound.
 func NewKeyRangeLowerBound(lower js.Value, open bool) (*KeyRange, error) {
-	keyRange, err := jsIDBKeyRange.Call("lowerBound", lower, open)
+	lowerKey, err := parseJSKey(safejs.Safe(lower))
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, err
 	}
-	return wrapKeyRange(keyRange), nil
+	return NewKeyRange(idbkey.LowerBound(lowerKey, open)), nil
 }
 
 // NewKeyRangeUpperBound creates a new key range with only an upper bound.
 func NewKeyRangeUpperBound(upper js.Value, open bool) (*KeyRange, error) {
-	keyRange, err := jsIDBKeyRange.Call("upperBound", upper, open)
+	upperKey, err := parseJSKey(safejs.Safe(upper))
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, err
 	}
-	return wrapKeyRange(keyRange), nil
+	return NewKeyRange(idbkey.UpperBound(upperKey, open)), nil
 }
 
 // NewKeyRangeOnly creates a new key range containing a single value.
 func NewKeyRangeOnly(only js.Value) (*KeyRange, error) {
-	keyRange, err := jsIDBKeyRange.Call("only", only)
+	onlyKey, err := parseJSKey(safejs.Safe(only))
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRange(idbkey.Only(onlyKey)), nil
+}
+
+// NewKeyRangePrefix creates a new key range containing every key starting with prefix.
+// Prefix can be a string, []byte, or []interface{} of compound key components (see NewCompoundKey), or a js.Value of one of those.
+//
+// Strings are matched by UTF-16 code units, binary keys by bytes, and array keys by their leading components.
+func NewKeyRangePrefix(prefix interface{}) (*KeyRange, error) {
+	prefixKey, err := parseKey(prefix)
+	if err != nil {
+		return nil, err
+	}
+	keyRange, err := idbkey.Prefix(prefixKey)
 	if err != nil {
-		return nil, tryAsDOMException(err)
+		return nil, tryAsDataError(err)
+	}
+	return NewKeyRange(keyRange), nil
+}
+
+// Range returns the Go-native range of keys in this key range
+func (k *KeyRange) Range() idbkey.Range {
+	return k.keyRange
+}
+
+// String returns a human-readable representation of the key range in interval notation, like [1, 5)
+func (k *KeyRange) String() string {
+	return k.keyRange.String()
+}
+
+// Equal returns true if k and other contain exactly the same keys. A nil KeyRange only equals another nil KeyRange.
+func (k *KeyRange) Equal(other *KeyRange) bool {
+	if k == nil || other == nil {
+		return k == other
+	}
+	return k.keyRange.Equal(other.keyRange)
+}
+
+// jsValue returns the JavaScript IDBKeyRange for this key range, creating it on first use
+func (k *KeyRange) jsValue() (safejs.Value, error) {
+	k.jsOnce.Do(func() {
+		k.jsKeyRange, k.jsErr = newJSKeyRange(k.keyRange)
+	})
+	return k.jsKeyRange, k.jsErr
+}
+
+func newJSKeyRange(keyRange idbkey.Range) (safejs.Value, error) {
+	lowerKey, hasLower := keyRange.Lower()
+	upperKey, hasUpper := keyRange.Upper()
+	var lower, upper safejs.Value
+	var err error
+	if hasLower {
+		lower, err = jsKey(lowerKey)
+		if err != nil {
+			return safejs.Value{}, err
+		}
+	}
+	if hasUpper {
+		upper, err = jsKey(upperKey)
+		if err != nil {
+			return safejs.Value{}, err
+		}
 	}
-	return wrapKeyRange(keyRange), nil
+	var jsKeyRange safejs.Value
+	switch {
+	case hasLower && hasUpper:
+		jsKeyRange, err = jsIDBKeyRange.Call("bound", lower, upper, keyRange.LowerOpen(), keyRange.UpperOpen())
+	case hasLower:
+		jsKeyRange, err = jsIDBKeyRange.Call("lowerBound", lower, keyRange.LowerOpen())
+	case hasUpper:
+		jsKeyRange, err = jsIDBKeyRange.Call("upperBound", upper, keyRange.UpperOpen())
+	default:
+		// IDBKeyRange requires at least one bound, and no key is less than -Infinity
+		jsKeyRange, err = jsIDBKeyRange.Call("lowerBound", math.Inf(-1), false)
+	}
+	return jsKeyRange, tryAsDOMException(err)
 }
 
 // Lower returns the lower bound of the key range.
 func (k *KeyRange) Lower() (js.Value, error) {
-	lower, err := k.jsKeyRange.Get("lower")
-	return safejs.Unsafe(lower), err
+	lower, ok := k.keyRange.Lower()
+	if !ok {
+		return js.Undefined(), nil
+	}
+	value, err := jsKey(lower)
+	return safejs.Unsafe(value), err
 }
 
 // Upper returns the upper bound of the key range.
 func (k *KeyRange) Upper() (js.Value, error) {
-	upper, err := k.jsKeyRange.Get("upper")
-	return safejs.Unsafe(upper), err
+	upper, ok := k.keyRange.Upper()
+	if !ok {
+		return js.Undefined(), nil
+	}
+	value, err := jsKey(upper)
+	return safejs.Unsafe(value), err
 }
 
 // LowerOpen returns false if the lower-bound value is included in the key range.
 func (k *KeyRange) LowerOpen() (bool, error) {
-	lowerOpen, err := k.jsKeyRange.Get("lowerOpen")
-	if err != nil {
-		return false, err
-	}
-	return lowerOpen.Bool()
+	return k.keyRange.LowerOpen(), nil
 }
 
 // UpperOpen returns false if the upper-bound value is included in the key range.
 func (k *KeyRange) UpperOpen() (bool, error) {
-	upperOpen, err := k.jsKeyRange.Get("upperOpen")
-	if err != nil {
-		return false, err
-	}
-	return upperOpen.Bool()
+	return k.keyRange.UpperOpen(), nil
 }
 
 // Includes returns a boolean indicating whether a specified key is inside the key range.
 func (k *KeyRange) Includes(key js.Value) (bool, error) {
-	includes, err := k.jsKeyRange.Call("includes", key)
+	parsedKey, err := parseJSKey(safejs.Safe(key))
 	if err != nil {
-		return false, tryAsDOMException(err)
+		return false, err
+	}
+	return k.keyRange.Includes(parsedKey), nil
+}
+
+// Intersect returns a new key range containing only the keys inside both k and other. Returns false if the key ranges do not overlap.
+func (k *KeyRange) Intersect(other *KeyRange) (*KeyRange, bool) {
+	intersection, ok := k.keyRange.Intersect(other.keyRange)
+	if !ok {
+		return nil, false
+	}
+	return NewKeyRange(intersection), true
+}
+
+// Union returns the key ranges containing every key inside either k or other. Returns 1 key range if they overlap or touch, otherwise returns both, sorted by their lower bounds.
+func (k *KeyRange) Union(other *KeyRange) []*KeyRange {
+	return UnionKeyRanges(k, other)
+}
+
+// UnionKeyRanges returns the smallest list of key ranges containing every key inside keyRanges. The result does not overlap and is sorted by lower bounds.
+func UnionKeyRanges(keyRanges ...*KeyRange) []*KeyRange {
+	ranges := make([]idbkey.Range, 0, len(keyRanges))
+	for _, keyRange := range keyRanges {
+		ranges = append(ranges, keyRange.keyRange)
+	}
+	unions := idbkey.Union(ranges...)
+	unionKeyRanges := make([]*KeyRange, 0, len(unions))
+	for _, union := range unions {
+		unionKeyRanges = append(unionKeyRanges, NewKeyRange(union))
 	}
-	return includes.Bool()
+	return unionKeyRanges
 }
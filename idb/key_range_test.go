@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
 )
 
 func TestNewKeyRangeBound(t *testing.T) {
@@ -188,4 +189,280 @@ func TestKeyRangeBoundProperties(t *testing.T) {
 	upperOpen, err := keyRange.UpperOpen()
 	assert.NoError(t, err)
 	assert.Equal(t, true, upperOpen)
+
+	assert.Equal(t, "[0, 100)", keyRange.String())
+	assert.Equal(t, true, keyRange.Equal(NewKeyRange(keyRange.Range())))
+}
+
+func TestNewKeyRangePrefix(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name           string
+		prefix         interface{}
+		key            js.Value
+		expectIncludes bool
+	}{
+		{name: "string prefix", prefix: "foo/", key: js.ValueOf("foo/"), expectIncludes: true},
+		{name: "string with prefix", prefix: "foo/", key: js.ValueOf("foo/bar"), expectIncludes: true},
+		{name: "string with max suffix", prefix: "foo/", key: js.ValueOf("foo/\uFFFF\uFFFF"), expectIncludes: true},
+		{name: "string without prefix", prefix: "foo/", key: js.ValueOf("foo0"), expectIncludes: false},
+		{name: "string smaller than prefix", prefix: "foo/", key: js.ValueOf("foo"), expectIncludes: false},
+		{name: "string with max prefix", prefix: "\uFFFF", key: js.ValueOf("\uFFFF\uFFFF"), expectIncludes: true},
+		{name: "string max prefix excludes other types", prefix: "\uFFFF", key: js.ValueOf([]interface{}{}), expectIncludes: false},
+		{name: "empty string prefix", prefix: "", key: js.ValueOf("a"), expectIncludes: true},
+		{name: "empty string prefix excludes numbers", prefix: "", key: js.ValueOf(1), expectIncludes: false},
+		{name: "array with prefix", prefix: []interface{}{"a"}, key: js.ValueOf([]interface{}{"a", 1}), expectIncludes: true},
+		{name: "array without prefix", prefix: []interface{}{"a"}, key: js.ValueOf([]interface{}{"b"}), expectIncludes: false},
+		{name: "js string prefix", prefix: js.ValueOf("a"), key: js.ValueOf("ab"), expectIncludes: true},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			keyRange, err := NewKeyRangePrefix(tc.prefix)
+			assert.NoError(t, err)
+			includes, err := keyRange.Includes(tc.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectIncludes, includes)
+		})
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		t.Parallel()
+		keyRange, err := NewKeyRangePrefix([]byte{1, 0xFF})
+		assert.NoError(t, err)
+		for _, tc := range []struct {
+			key            []byte
+			expectIncludes bool
+		}{
+			{key: []byte{1, 0xFF}, expectIncludes: true},
+			{key: []byte{1, 0xFF, 0xFF}, expectIncludes: true},
+			{key: []byte{1, 0xFE}, expectIncludes: false},
+			{key: []byte{2}, expectIncludes: false},
+		} {
+			k, err := parseKey(tc.key)
+			assert.NoError(t, err)
+			value, err := jsKey(k)
+			assert.NoError(t, err)
+			includes, err := keyRange.Includes(safejs.Unsafe(value))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectIncludes, includes)
+		}
+	})
+
+	t.Run("invalid prefix", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewKeyRangePrefix(1)
+		assert.ErrorIs(t, err, NewDOMException("DataError"))
+	})
+}
+
+func TestKeyRangeEqual(t *testing.T) {
+	t.Parallel()
+	mustRange := func(keyRange *KeyRange, err error) *KeyRange {
+		assert.NoError(t, err)
+		return keyRange
+	}
+	keyRange := mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(10), false, true))
+	for _, tc := range []struct {
+		name   string
+		a, b   *KeyRange
+		expect bool
+	}{
+		{
+			name:   "same bounds",
+			a:      keyRange,
+			b:      mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(10), false, true)),
+			expect: true,
+		},
+		{
+			name:   "different open bound",
+			a:      keyRange,
+			b:      mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(10), false, false)),
+			expect: false,
+		},
+		{
+			name:   "nil other",
+			a:      keyRange,
+			b:      nil,
+			expect: false,
+		},
+		{
+			name:   "nil receiver",
+			a:      nil,
+			b:      keyRange,
+			expect: false,
+		},
+		{
+			name:   "both nil",
+			a:      nil,
+			b:      nil,
+			expect: true,
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expect, tc.a.Equal(tc.b))
+		})
+	}
+}
+
+func TestKeyRangeIntersect(t *testing.T) {
+	t.Parallel()
+	mustRange := func(keyRange *KeyRange, err error) *KeyRange {
+		assert.NoError(t, err)
+		return keyRange
+	}
+	for _, tc := range []struct {
+		name        string
+		a, b        *KeyRange
+		expectEmpty bool
+		expectLower interface{}
+		expectUpper interface{}
+		expectOpen  [2]bool
+	}{
+		{
+			name:        "overlap",
+			a:           mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(10), false, false)),
+			b:           mustRange(NewKeyRangeBound(js.ValueOf(5), js.ValueOf(15), true, false)),
+			expectLower: 5,
+			expectUpper: 10,
+			expectOpen:  [2]bool{true, false},
+		},
+		{
+			name:        "unbounded",
+			a:           mustRange(NewKeyRangeLowerBound(js.ValueOf(0), false)),
+			b:           mustRange(NewKeyRangeUpperBound(js.ValueOf(10), true)),
+			expectLower: 0,
+			expectUpper: 10,
+			expectOpen:  [2]bool{false, true},
+		},
+		{
+			name:        "same key with open bound",
+			a:           mustRange(NewKeyRangeLowerBound(js.ValueOf(0), true)),
+			b:           mustRange(NewKeyRangeLowerBound(js.ValueOf(0), false)),
+			expectLower: 0,
+			expectUpper: js.Undefined(),
+			expectOpen:  [2]bool{true, true},
+		},
+		{
+			name:        "touching",
+			a:           mustRange(NewKeyRangeUpperBound(js.ValueOf(5), false)),
+			b:           mustRange(NewKeyRangeLowerBound(js.ValueOf(5), false)),
+			expectLower: 5,
+			expectUpper: 5,
+		},
+		{
+			name:        "touching open",
+			a:           mustRange(NewKeyRangeUpperBound(js.ValueOf(5), true)),
+			b:           mustRange(NewKeyRangeLowerBound(js.ValueOf(5), false)),
+			expectEmpty: true,
+		},
+		{
+			name:        "disjoint",
+			a:           mustRange(NewKeyRangeOnly(js.ValueOf("a"))),
+			b:           mustRange(NewKeyRangeOnly(js.ValueOf("b"))),
+			expectEmpty: true,
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			intersection, ok := tc.a.Intersect(tc.b)
+			assert.Equal(t, !tc.expectEmpty, ok)
+			if tc.expectEmpty {
+				assert.Zero(t, intersection)
+				return
+			}
+			lower, err := intersection.Lower()
+			assert.NoError(t, err)
+			upper, err := intersection.Upper()
+			assert.NoError(t, err)
+			lowerOpen, err := intersection.LowerOpen()
+			assert.NoError(t, err)
+			upperOpen, err := intersection.UpperOpen()
+			assert.NoError(t, err)
+			assert.Equal(t, js.ValueOf(tc.expectLower), lower)
+			assert.Equal(t, js.ValueOf(tc.expectUpper), upper)
+			assert.Equal(t, tc.expectOpen, [2]bool{lowerOpen, upperOpen})
+		})
+	}
+}
+
+func TestUnionKeyRanges(t *testing.T) {
+	t.Parallel()
+	mustRange := func(keyRange *KeyRange, err error) *KeyRange {
+		assert.NoError(t, err)
+		return keyRange
+	}
+	type bounds struct {
+		Lower, Upper         interface{}
+		LowerOpen, UpperOpen bool
+	}
+	for _, tc := range []struct {
+		name         string
+		keyRanges    []*KeyRange
+		expectBounds []bounds
+	}{
+		{
+			name: "overlap",
+			keyRanges: []*KeyRange{
+				mustRange(NewKeyRangeBound(js.ValueOf(5), js.ValueOf(15), true, false)),
+				mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(10), false, true)),
+			},
+			expectBounds: []bounds{{Lower: 0, Upper: 15}},
+		},
+		{
+			name: "touching",
+			keyRanges: []*KeyRange{
+				mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(5), false, true)),
+				mustRange(NewKeyRangeBound(js.ValueOf(5), js.ValueOf(10), false, true)),
+			},
+			expectBounds: []bounds{{Lower: 0, Upper: 10, UpperOpen: true}},
+		},
+		{
+			name: "disjoint",
+			keyRanges: []*KeyRange{
+				mustRange(NewKeyRangeOnly(js.ValueOf("b"))),
+				mustRange(NewKeyRangeBound(js.ValueOf(0), js.ValueOf(5), false, true)),
+				mustRange(NewKeyRangeBound(js.ValueOf(5), js.ValueOf(10), true, true)),
+			},
+			expectBounds: []bounds{
+				{Lower: 0, Upper: 5, UpperOpen: true},
+				{Lower: 5, Upper: 10, LowerOpen: true, UpperOpen: true},
+				{Lower: "b", Upper: "b"},
+			},
+		},
+		{
+			name: "unbounded",
+			keyRanges: []*KeyRange{
+				mustRange(NewKeyRangeUpperBound(js.ValueOf(10), false)),
+				mustRange(NewKeyRangeLowerBound(js.ValueOf(5), false)),
+			},
+			expectBounds: []bounds{{Lower: js.Undefined(), Upper: js.Undefined(), LowerOpen: true, UpperOpen: true}},
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			unions := UnionKeyRanges(tc.keyRanges...)
+			var actualBounds []bounds
+			for _, union := range unions {
+				lower, err := union.Lower()
+				assert.NoError(t, err)
+				upper, err := union.Upper()
+				assert.NoError(t, err)
+				lowerOpen, err := union.LowerOpen()
+				assert.NoError(t, err)
+				upperOpen, err := union.UpperOpen()
+				assert.NoError(t, err)
+				actualBounds = append(actualBounds, bounds{Lower: lower, Upper: upper, LowerOpen: lowerOpen, UpperOpen: upperOpen})
+			}
+			for i := range tc.expectBounds {
+				tc.expectBounds[i].Lower = js.ValueOf(tc.expectBounds[i].Lower)
+				tc.expectBounds[i].Upper = js.ValueOf(tc.expectBounds[i].Upper)
+			}
+			assert.Equal(t, tc.expectBounds, actualBounds)
+		})
+	}
 }
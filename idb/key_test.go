@@ -0,0 +1,173 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"math"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb/idbkey"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/safejs"
+)
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+	someTime := time.UnixMilli(1000)
+	for _, tc := range []struct {
+		name      string
+		value     interface{}
+		expectKey idbkey.Key
+		expectErr error
+	}{
+		{
+			name:      "int",
+			value:     1,
+			expectKey: idbkey.Number(1),
+		},
+		{
+			name:      "float",
+			value:     1.5,
+			expectKey: idbkey.Number(1.5),
+		},
+		{
+			name:      "NaN",
+			value:     math.NaN(),
+			expectErr: NewDOMException("DataError"),
+		},
+		{
+			name:      "string",
+			value:     "a",
+			expectKey: idbkey.String("a"),
+		},
+		{
+			name:      "time",
+			value:     someTime,
+			expectKey: idbkey.Date(someTime),
+		},
+		{
+			name:      "bytes",
+			value:     []byte("a"),
+			expectKey: idbkey.Binary([]byte("a")),
+		},
+		{
+			name:      "array",
+			value:     []interface{}{1, "a"},
+			expectKey: idbkey.Array(idbkey.Number(1), idbkey.String("a")),
+		},
+		{
+			name:      "array with js value",
+			value:     []interface{}{js.ValueOf(1), "a"},
+			expectKey: idbkey.Array(idbkey.Number(1), idbkey.String("a")),
+		},
+		{
+			name:      "js array",
+			value:     js.ValueOf([]interface{}{1, "a"}),
+			expectKey: idbkey.Array(idbkey.Number(1), idbkey.String("a")),
+		},
+		{
+			name:      "js date",
+			value:     js.Global().Get("Date").New(1000),
+			expectKey: idbkey.Date(someTime),
+		},
+		{
+			name:      "idbkey",
+			value:     idbkey.String("a"),
+			expectKey: idbkey.String("a"),
+		},
+		{
+			name:      "bool",
+			value:     true,
+			expectErr: NewDOMException("DataError"),
+		},
+		{
+			name:      "js object",
+			value:     js.ValueOf(map[string]interface{}{}),
+			expectErr: NewDOMException("DataError"),
+		},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			k, err := parseKey(tc.value)
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectKey, k)
+		})
+	}
+}
+
+func TestJSKey(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		key  idbkey.Key
+	}{
+		{name: "number", key: idbkey.Number(1)},
+		{name: "date", key: idbkey.Date(time.UnixMilli(1000))},
+		{name: "string", key: idbkey.String("a")},
+		{name: "binary", key: idbkey.Binary([]byte("a"))},
+		{name: "array", key: idbkey.Array(idbkey.Number(1), idbkey.String("a"), idbkey.Array(idbkey.Binary([]byte("b"))))},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := jsKey(tc.key)
+			assert.NoError(t, err)
+			roundTripKey, err := parseJSKey(value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.key, roundTripKey)
+		})
+	}
+}
+
+func TestParseKeyValue(t *testing.T) {
+	t.Parallel()
+	k, err := ParseKey(js.ValueOf([]interface{}{1, "a"}))
+	assert.NoError(t, err)
+	assert.Equal(t, idbkey.Array(idbkey.Number(1), idbkey.String("a")), k)
+
+	_, err = ParseKey(js.ValueOf(true))
+	assert.ErrorIs(t, err, NewDOMException("DataError"))
+}
+
+func TestKeyValue(t *testing.T) {
+	t.Parallel()
+	k := idbkey.Array(idbkey.Date(time.UnixMilli(1000)), idbkey.Binary([]byte("a")))
+	value, err := KeyValue(k)
+	assert.NoError(t, err)
+	roundTripKey, err := ParseKey(value)
+	assert.NoError(t, err)
+	assert.Equal(t, k, roundTripKey)
+}
+
+func TestKeyCompareMatchesIndexedDB(t *testing.T) {
+	t.Parallel()
+	keys := []idbkey.Key{
+		idbkey.Number(math.Inf(-1)),
+		idbkey.Number(1.5),
+		idbkey.Date(time.UnixMilli(0)),
+		idbkey.String(""),
+		idbkey.String("a\x00"),
+		idbkey.String("\U0001F600"),
+		idbkey.String("\uE000"),
+		idbkey.Binary([]byte{0}),
+		idbkey.Binary([]byte{1}),
+		idbkey.Array(),
+		idbkey.Array(idbkey.Number(1), idbkey.Number(1)),
+		idbkey.Array(idbkey.String("a")),
+	}
+	for _, a := range keys {
+		for _, b := range keys {
+			jsA, err := jsKey(a)
+			assert.NoError(t, err)
+			jsB, err := jsKey(b)
+			assert.NoError(t, err)
+			compare, err := Global().CompareKeys(safejs.Unsafe(jsA), safejs.Unsafe(jsB))
+			assert.NoError(t, err)
+			assert.Equal(t, compare, a.Compare(b))
+		}
+	}
+}
@@ -0,0 +1,339 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package kv is a simple key-value store backed by an IndexedDB object store.
+//
+// Keys are strings and values are []byte, stored as Uint8Arrays. Each Store method runs in its own short transaction. Use Store.Batch to run several operations in one transaction.
+package kv
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+// ErrNotFound is returned when getting a key which does not exist
+var ErrNotFound = errors.New("kv: key not found")
+
+// errConnectionClosed is returned when creating a transaction on a closed database connection
+var errConnectionClosed = idb.NewDOMException("InvalidStateError")
+
+// Store is a key-value store in an IndexedDB object store
+type Store struct {
+	factory   *idb.Factory
+	dbName    string
+	storeName string
+
+	mu     sync.Mutex
+	db     *idb.Database
+	closed bool
+}
+
+// Open opens the key-value store named storeName in the global IndexedDB database named dbName, creating them if they don't exist.
+// Several stores can share the same database. Opening a store missing from the database upgrades it, which closes every other connection to it, so the other stores reopen their connection on their next call.
+func Open(ctx context.Context, dbName, storeName string) (*Store, error) {
+	return OpenFactory(ctx, idb.Global(), dbName, storeName)
+}
+
+// OpenFactory is the same as Open, but opens the database in the given factory. Useful for storage buckets.
+func OpenFactory(ctx context.Context, factory *idb.Factory, dbName, storeName string) (*Store, error) {
+	db, err := openDB(ctx, factory, dbName, 0, storeName)
+	if err != nil {
+		return nil, err
+	}
+	storeNames, err := db.ObjectStoreNames()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	for _, name := range storeNames {
+		if name == storeName {
+			return newStore(factory, dbName, storeName, db), nil
+		}
+	}
+
+	// store is missing from an existing database, so upgrade to create it
+	version, err := db.Version()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	db, err = openDB(ctx, factory, dbName, version+1, storeName)
+	if err != nil {
+		return nil, err
+	}
+	return newStore(factory, dbName, storeName, db), nil
+}
+
+func newStore(factory *idb.Factory, dbName, storeName string, db *idb.Database) *Store {
+	return &Store{
+		factory:   factory,
+		dbName:    dbName,
+		storeName: storeName,
+		db:        db,
+	}
+}
+
+func openDB(ctx context.Context, factory *idb.Factory, dbName string, version uint, storeName string) (*idb.Database, error) {
+	req, err := factory.Open(ctx, dbName, version, func(db *idb.Database, oldVersion, newVersion uint) error {
+		storeNames, err := db.ObjectStoreNames()
+		if err != nil {
+			return err
+		}
+		for _, name := range storeNames {
+			if name == storeName {
+				return nil
+			}
+		}
+		_, err = db.CreateObjectStore(storeName, idb.ObjectStoreOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req.Await(ctx)
+}
+
+// Database returns the store's current database connection
+func (s *Store) Database() *idb.Database {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db
+}
+
+// Close closes the store's database connection
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.db.Close()
+}
+
+// run runs fn in a new transaction on the store. Reopens the database connection once if it was closed by another connection's upgrade.
+func (s *Store) run(ctx context.Context, mode idb.TransactionMode, fn func(*Txn) error) error {
+	db := s.Database()
+	started := false
+	err := runDB(ctx, db, mode, s.storeName, func(txn *Txn) error {
+		started = true
+		return fn(txn)
+	})
+	if started || !errors.Is(err, errConnectionClosed) {
+		return err
+	}
+	db, err = s.reopen(ctx, db, err)
+	if err != nil {
+		return err
+	}
+	return runDB(ctx, db, mode, s.storeName, fn)
+}
+
+func runDB(ctx context.Context, db *idb.Database, mode idb.TransactionMode, storeName string, fn func(*Txn) error) error {
+	return txnutil.Run(ctx, db, mode, []string{storeName}, func(txn *idb.Transaction) error {
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return err
+		}
+		return fn(&Txn{txn: txn, store: store})
+	})
+}
+
+// reopen replaces the closed connection db with a new one. Returns closeErr if the store was closed with Close.
+func (s *Store) reopen(ctx context.Context, db *idb.Database, closeErr error) (*idb.Database, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, closeErr
+	}
+	if s.db != db {
+		return s.db, nil // already reopened by another call
+	}
+	newDB, err := openDB(ctx, s.factory, s.dbName, 0, s.storeName)
+	if err != nil {
+		return nil, err
+	}
+	s.db = newDB
+	return newDB, nil
+}
+
+// Batch runs fn in a single readwrite transaction. If fn returns an error, the transaction is aborted and none of its changes are saved.
+func (s *Store) Batch(ctx context.Context, fn func(*Txn) error) error {
+	return s.run(ctx, idb.TransactionReadWrite, fn)
+}
+
+// Get returns the value for key. Returns ErrNotFound if key does not exist.
+func (s *Store) Get(ctx context.Context, key string) (value []byte, err error) {
+	err = s.run(ctx, idb.TransactionReadOnly, func(txn *Txn) error {
+		value, err = txn.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+// Has returns true if key exists
+func (s *Store) Has(ctx context.Context, key string) (exists bool, err error) {
+	err = s.run(ctx, idb.TransactionReadOnly, func(txn *Txn) error {
+		exists, err = txn.Has(ctx, key)
+		return err
+	})
+	return exists, err
+}
+
+// Set sets key's value
+func (s *Store) Set(ctx context.Context, key string, value []byte) error {
+	return s.Batch(ctx, func(txn *Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+// SetMany sets the value of every key in values in a single transaction
+func (s *Store) SetMany(ctx context.Context, values map[string][]byte) error {
+	return s.Batch(ctx, func(txn *Txn) error {
+		for key, value := range values {
+			if err := txn.Set(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete deletes key. Does nothing if key does not exist.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.Batch(ctx, func(txn *Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+// Keys returns all keys in sorted order
+func (s *Store) Keys(ctx context.Context) (keys []string, err error) {
+	err = s.run(ctx, idb.TransactionReadOnly, func(txn *Txn) error {
+		keys, err = txn.Keys(ctx)
+		return err
+	})
+	return keys, err
+}
+
+// Scan calls fn with every key starting with prefix and its value, in sorted order. Stops if fn returns an error, and returns that error.
+func (s *Store) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	return s.run(ctx, idb.TransactionReadOnly, func(txn *Txn) error {
+		return txn.Scan(ctx, prefix, fn)
+	})
+}
+
+// Txn is a transaction on a Store, used in Store.Batch
+type Txn struct {
+	txn   *idb.Transaction
+	store *idb.ObjectStore
+}
+
+// Get returns the value for key. Returns ErrNotFound if key does not exist.
+func (t *Txn) Get(ctx context.Context, key string) ([]byte, error) {
+	jsKey, err := jsKeyOf(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := t.store.GetBytes(jsKey)
+	if err != nil {
+		return nil, err
+	}
+	value, err := req.Await(ctx)
+	if err == nil && value == nil {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Has returns true if key exists
+func (t *Txn) Has(ctx context.Context, key string) (bool, error) {
+	jsKey, err := jsKeyOf(key)
+	if err != nil {
+		return false, err
+	}
+	req, err := t.store.CountKey(jsKey)
+	if err != nil {
+		return false, err
+	}
+	count, err := req.Await(ctx)
+	return count > 0, err
+}
+
+// Set sets key's value. Errors writing the value are returned when the transaction completes.
+func (t *Txn) Set(key string, value []byte) error {
+	jsKey, err := jsKeyOf(key)
+	if err != nil {
+		return err
+	}
+	_, err = t.store.PutBytes(jsKey, value)
+	return err
+}
+
+// Delete deletes key. Errors deleting the key are returned when the transaction completes.
+func (t *Txn) Delete(key string) error {
+	jsKey, err := jsKeyOf(key)
+	if err != nil {
+		return err
+	}
+	_, err = t.store.Delete(jsKey)
+	return err
+}
+
+// Keys returns all keys in sorted order
+func (t *Txn) Keys(ctx context.Context) ([]string, error) {
+	req, err := t.store.GetAllKeys()
+	if err != nil {
+		return nil, err
+	}
+	jsKeys, err := req.Await(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(jsKeys))
+	for _, jsKey := range jsKeys {
+		key, err := safejs.Safe(jsKey).String()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Scan calls fn with every key starting with prefix and its value, in sorted order. Stops if fn returns an error, and returns that error.
+func (t *Txn) Scan(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	keyRange, err := idb.NewKeyRangePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	req, err := t.store.OpenCursorRange(keyRange, idb.CursorNext)
+	if err != nil {
+		return err
+	}
+	return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		jsKey, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+		key, err := safejs.Safe(jsKey).String()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.ValueBytes()
+		if err != nil {
+			return err
+		}
+		return fn(key, value)
+	})
+}
+
+func jsKeyOf(key string) (js.Value, error) {
+	jsKey, err := safejs.ValueOf(key)
+	return safejs.Unsafe(jsKey), err
+}
@@ -0,0 +1,166 @@
+//go:build js && wasm
+// +build js,wasm
+
+package kv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testStore(tb testing.TB) *Store {
+	tb.Helper()
+	store, err := Open(context.Background(), idbtest.DBName(tb), "mystore")
+	if !assert.NoError(tb, err) {
+		tb.FailNow()
+	}
+	tb.Cleanup(func() {
+		assert.NoError(tb, store.Close())
+	})
+	return store
+}
+
+func TestOpenMultipleStores(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dbName := idbtest.DBName(t)
+	store1, err := Open(ctx, dbName, "store1")
+	assert.NoError(t, err)
+	assert.NoError(t, store1.Set(ctx, "key", []byte("value 1")))
+	assert.NoError(t, store1.Close())
+
+	store2, err := Open(ctx, dbName, "store2")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, store2.Close())
+	}()
+	names, err := store2.Database().ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"store1", "store2"}, names)
+	_, err = store2.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOpenStoreKeepsOtherStoresOpen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dbName := idbtest.DBName(t)
+	store1, err := Open(ctx, dbName, "store1")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, store1.Close())
+	}()
+	assert.NoError(t, store1.Set(ctx, "key", []byte("value 1")))
+
+	store2, err := Open(ctx, dbName, "store2")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, store2.Close())
+	}()
+	assert.NoError(t, store2.Set(ctx, "key", []byte("value 2")))
+
+	value, err := store1.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value 1"), value)
+	assert.NoError(t, store1.Set(ctx, "key", []byte("value 3")))
+	value, err = store2.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value 2"), value)
+}
+
+func TestStoreGetSet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+
+	_, err := store.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Set(ctx, "key", []byte("value")))
+	value, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	assert.NoError(t, store.Set(ctx, "empty", nil))
+	value, err = store.Get(ctx, "empty")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, value)
+}
+
+func TestStoreHasDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	assert.NoError(t, store.Set(ctx, "key", []byte("value")))
+
+	exists, err := store.Has(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, true, exists)
+
+	assert.NoError(t, store.Delete(ctx, "key"))
+	exists, err = store.Has(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, false, exists)
+	assert.NoError(t, store.Delete(ctx, "key"))
+}
+
+func TestStoreKeysScan(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	assert.NoError(t, store.SetMany(ctx, map[string][]byte{
+		"a/1": []byte("1"),
+		"a/2": []byte("2"),
+		"b/1": []byte("3"),
+	}))
+
+	keys, err := store.Keys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/1", "a/2", "b/1"}, keys)
+
+	var scanned []string
+	assert.NoError(t, store.Scan(ctx, "a/", func(key string, value []byte) error {
+		scanned = append(scanned, key+"="+string(value))
+		return nil
+	}))
+	assert.Equal(t, []string{"a/1=1", "a/2=2"}, scanned)
+
+	stopErr := errors.New("stop")
+	err = store.Scan(ctx, "", func(key string, value []byte) error {
+		return stopErr
+	})
+	assert.ErrorIs(t, err, stopErr)
+}
+
+func TestStoreBatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t)
+	assert.NoError(t, store.Set(ctx, "count", []byte{1}))
+
+	assert.NoError(t, store.Batch(ctx, func(txn *Txn) error {
+		value, err := txn.Get(ctx, "count")
+		if err != nil {
+			return err
+		}
+		return txn.Set("count", []byte{value[0] + 1})
+	}))
+	value, err := store.Get(ctx, "count")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2}, value)
+
+	batchErr := errors.New("some error")
+	err = store.Batch(ctx, func(txn *Txn) error {
+		if err := txn.Set("count", []byte{100}); err != nil {
+			return err
+		}
+		return batchErr
+	})
+	assert.ErrorIs(t, err, batchErr)
+	value, err = store.Get(ctx, "count")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2}, value)
+}
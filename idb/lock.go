@@ -0,0 +1,163 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/promise"
+	"github.com/hack-pad/safejs"
+)
+
+const lockNamePrefix = "go-indexeddb-lock:"
+
+var (
+	jsAbortController safejs.Value
+	jsLocks           safejs.Value
+
+	// ErrLockNotAvailable is returned when LockOptions.IfAvailable is set and the lock is already held
+	ErrLockNotAvailable = errors.New("Lock not available")
+
+	errLocksUnsupported = errors.New("Web Locks API is not supported")
+)
+
+func init() {
+	var err error
+	jsAbortController, err = safejs.Global().Get("AbortController")
+	if err != nil {
+		panic(err)
+	}
+	navigator, err := safejs.Global().Get("navigator")
+	if err != nil {
+		panic(err)
+	}
+	if !navigator.IsUndefined() {
+		jsLocks, err = navigator.Get("locks")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LockMode defines whether a lock can be held by one or many holders at once
+type LockMode int
+
+const (
+	// LockExclusive allows only one holder of the lock at a time. This is the default.
+	LockExclusive LockMode = iota
+	// LockShared allows many holders of the lock at a time, while no LockExclusive holder exists.
+	LockShared
+)
+
+func (m LockMode) String() string {
+	switch m {
+	case LockShared:
+		return "shared"
+	default:
+		return "exclusive"
+	}
+}
+
+// LockOptions contains all available options for acquiring a lock
+type LockOptions struct {
+	Mode LockMode
+	// IfAvailable only acquires the lock if it can be granted immediately. Otherwise, returns ErrLockNotAvailable without running fn.
+	IfAvailable bool
+	// Steal releases any held locks with the same name and acquires the lock immediately. The stolen locks' contexts are canceled.
+	Steal bool
+}
+
+// WithLock acquires the named lock, runs fn, then releases the lock when fn returns.
+// The lock is shared by every tab and worker of the same origin, which makes it useful for workflows spanning multiple transactions. Lock names are scoped to this database.
+//
+// Canceling ctx abandons waiting for the lock, or cancels fn's context once the lock is held.
+func (db *Database) WithLock(ctx context.Context, name string, mode LockMode, fn func(ctx context.Context) error) error {
+	return db.WithLockOptions(ctx, name, LockOptions{Mode: mode}, fn)
+}
+
+// WithLockOptions is the same as WithLock, but with all available options
+func (db *Database) WithLockOptions(ctx context.Context, name string, options LockOptions, fn func(ctx context.Context) error) error {
+	if jsLocks.IsUndefined() {
+		return errLocksUnsupported
+	}
+	dbName, err := db.Name()
+	if err != nil {
+		return err
+	}
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	optionsMap := map[string]interface{}{
+		"mode":        options.Mode.String(),
+		"ifAvailable": options.IfAvailable,
+		"steal":       options.Steal,
+	}
+	if !options.IfAvailable && !options.Steal {
+		// abort signals are only supported while waiting for the lock
+		controller, err := jsAbortController.New()
+		if err != nil {
+			return err
+		}
+		signal, err := controller.Get("signal")
+		if err != nil {
+			return err
+		}
+		optionsMap["signal"] = signal
+		go func() {
+			<-lockCtx.Done()
+			_, _ = controller.Call("abort") // no-op once the lock is granted
+		}()
+	}
+
+	granted := make(chan struct{})
+	fnErr := make(chan error, 1)
+	callback, err := safejs.FuncOf(func(_ safejs.Value, args []safejs.Value) interface{} {
+		if len(args) == 0 || args[0].IsNull() {
+			return nil // not available
+		}
+		close(granted)
+		released, release, _, err := promise.New()
+		if err != nil {
+			fnErr <- err
+			return nil
+		}
+		go func() {
+			fnErr <- fn(lockCtx)
+			_ = release(nil)
+		}()
+		return released
+	})
+	if err != nil {
+		return err
+	}
+	defer callback.Release()
+	request, err := jsLocks.Call("request", lockNamePrefix+dbName+":"+name, optionsMap, callback)
+	if err != nil {
+		return tryAsDOMException(err)
+	}
+
+	// wait for the request to finish without ctx, which is handled by the abort signal
+	_, requestErr := promise.Await(context.Background(), request)
+	select {
+	case <-granted:
+	default:
+		if requestErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return tryAsDOMException(requestErr)
+		}
+		return ErrLockNotAvailable
+	}
+	if requestErr != nil {
+		// lock was stolen, so stop fn as soon as possible
+		cancel()
+		if err := <-fnErr; err != nil {
+			return err
+		}
+		return tryAsDOMException(requestErr)
+	}
+	return <-fnErr
+}
@@ -0,0 +1,136 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+// holdLock acquires the named lock in the background and holds it until the returned release func is called
+func holdLock(tb testing.TB, db *Database, name string, options LockOptions) (lockCtx context.Context, release func() error) {
+	tb.Helper()
+	acquired := make(chan context.Context)
+	releaseLock := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- db.WithLockOptions(context.Background(), name, options, func(ctx context.Context) error {
+			acquired <- ctx
+			<-releaseLock
+			return nil
+		})
+	}()
+	select {
+	case lockCtx = <-acquired:
+	case err := <-result:
+		tb.Fatal("Failed to acquire lock:", err)
+	}
+	return lockCtx, func() error {
+		close(releaseLock)
+		return <-result
+	}
+}
+
+func TestDatabaseWithLock(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	_, release := holdLock(t, db, "mylock", LockOptions{})
+
+	acquired := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- db.WithLock(context.Background(), "mylock", LockExclusive, func(context.Context) error {
+			close(acquired)
+			return nil
+		})
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Exclusive lock should not be acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.NoError(t, release())
+	assert.NoError(t, <-result)
+}
+
+func TestDatabaseWithLockShared(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	_, release := holdLock(t, db, "mylock", LockOptions{Mode: LockShared})
+	defer func() {
+		assert.NoError(t, release())
+	}()
+
+	ran := false
+	err := db.WithLock(context.Background(), "mylock", LockShared, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, true, ran)
+}
+
+func TestDatabaseWithLockIfAvailable(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	_, release := holdLock(t, db, "mylock", LockOptions{})
+
+	err := db.WithLockOptions(context.Background(), "mylock", LockOptions{IfAvailable: true}, func(context.Context) error {
+		t.Error("Should not run fn while lock is held")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrLockNotAvailable)
+
+	assert.NoError(t, release())
+	ran := false
+	err = db.WithLockOptions(context.Background(), "mylock", LockOptions{IfAvailable: true}, func(context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, true, ran)
+}
+
+func TestDatabaseWithLockSteal(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	lockCtx, release := holdLock(t, db, "mylock", LockOptions{})
+
+	err := db.WithLockOptions(context.Background(), "mylock", LockOptions{Steal: true}, func(context.Context) error {
+		<-lockCtx.Done() // stolen lock's context is canceled
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, release(), NewDOMException("AbortError"))
+}
+
+func TestDatabaseWithLockCanceled(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	_, release := holdLock(t, db, "mylock", LockOptions{})
+	defer func() {
+		assert.NoError(t, release())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := db.WithLock(ctx, "mylock", LockExclusive, func(context.Context) error {
+		t.Error("Should not run fn after canceling")
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDatabaseWithLockError(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	someErr := NewDOMException("SomeError")
+	err := db.WithLock(context.Background(), "mylock", LockExclusive, func(context.Context) error {
+		return someErr
+	})
+	assert.ErrorIs(t, err, someErr)
+}
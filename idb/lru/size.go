@@ -0,0 +1,94 @@
+//go:build js && wasm
+// +build js,wasm
+
+package lru
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsArrayBuffer safejs.Value
+	jsBlob        safejs.Value
+	jsJSON        safejs.Value
+)
+
+func init() {
+	for _, global := range []struct {
+		name  string
+		value *safejs.Value
+	}{
+		{"ArrayBuffer", &jsArrayBuffer},
+		{"Blob", &jsBlob},
+		{"JSON", &jsJSON},
+	} {
+		value, err := safejs.Global().Get(global.name)
+		if err != nil {
+			panic(err)
+		}
+		*global.value = value
+	}
+}
+
+// EstimateSize returns the approximate size of value in bytes.
+// Binary values and Blobs use their exact size, strings count 2 bytes per UTF-16 code unit, and other values are estimated from their JSON encoding. Returns 0 if value has no estimate.
+func EstimateSize(value js.Value) int64 {
+	jsValue := safejs.Safe(value)
+	switch jsValue.Type() {
+	case safejs.TypeString:
+		return stringSize(jsValue)
+	case safejs.TypeObject:
+	default:
+		return 0
+	}
+
+	if isView, err := jsArrayBuffer.Call("isView", jsValue); err == nil {
+		if truthy, _ := isView.Truthy(); truthy {
+			return intProperty(jsValue, "byteLength")
+		}
+	}
+	if isBuffer, err := jsValue.InstanceOf(jsArrayBuffer); err == nil && isBuffer {
+		return intProperty(jsValue, "byteLength")
+	}
+	if !jsBlob.IsUndefined() {
+		if isBlob, err := jsValue.InstanceOf(jsBlob); err == nil && isBlob {
+			return intProperty(jsValue, "size")
+		}
+	}
+	encoded, err := jsJSON.Call("stringify", jsValue)
+	if err != nil || encoded.Type() != safejs.TypeString {
+		return 0
+	}
+	return stringSize(encoded)
+}
+
+// stringSize returns the size of a string as UTF-16
+func stringSize(value safejs.Value) int64 {
+	str, err := value.String()
+	if err != nil {
+		return 0
+	}
+	var size int64
+	for _, r := range str {
+		if r >= 0x10000 {
+			size += 4 // surrogate pair
+		} else {
+			size += 2
+		}
+	}
+	return size
+}
+
+func intProperty(value safejs.Value, name string) int64 {
+	property, err := value.Get(name)
+	if err != nil {
+		return 0
+	}
+	n, err := property.Int()
+	if err != nil {
+		return 0
+	}
+	return int64(n)
+}
@@ -0,0 +1,35 @@
+//go:build js && wasm
+// +build js,wasm
+
+package lru
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestEstimateSize(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name     string
+		value    js.Value
+		expected int64
+	}{
+		{"undefined", js.Undefined(), 0},
+		{"number", js.ValueOf(1), 0},
+		{"string", js.ValueOf("abc"), 6},
+		{"Uint8Array", js.Global().Get("Uint8Array").New(10), 10},
+		{"Float64Array", js.Global().Get("Float64Array").New(2), 16},
+		{"ArrayBuffer", js.Global().Get("ArrayBuffer").New(7), 7},
+		{"Blob", js.Global().Get("Blob").New(js.ValueOf([]interface{}{"hello"})), 5},
+		{"object", js.ValueOf(map[string]interface{}{"a": 1}), 14},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, EstimateSize(tc.value))
+		})
+	}
+}
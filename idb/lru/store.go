@@ -0,0 +1,406 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package lru stores a size-bounded cache in IndexedDB, which evicts the least recently used entries.
+//
+// Each entry is stored in an envelope with its approximate size and last access time. An index on the access time finds the least recently used entries to evict, and a metadata record tracks the total count and size. Every write updates the metadata and evicts entries in the same transaction, so limits hold across tabs.
+//
+// Reads only need read-only transactions: access times are buffered in memory, then written with the next write or Store.Flush.
+package lru
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// IndexName is the name of the index on each entry's access time
+	IndexName = "$accessed"
+	// MetaKey is the key of the record tracking the cache's count and size. It's reserved, so don't use it as an entry's key.
+	MetaKey = "$lru"
+
+	valueField    = "value"
+	sizeField     = "size"
+	accessedField = "accessed"
+	expiresField  = "expires"
+
+	defaultAccessBatchSize     = 100
+	defaultAccessFlushInterval = 10 * time.Second
+)
+
+var (
+	jsMetaKey safejs.Value
+
+	// ErrTooLarge is returned when an entry's size is larger than Options.MaxBytes
+	ErrTooLarge = errors.New("lru: entry is larger than the cache")
+)
+
+func init() {
+	var err error
+	jsMetaKey, err = safejs.ValueOf(MetaKey)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Options contains all available options for an LRU Store
+type Options struct {
+	// MaxEntries is the maximum number of entries. Zero means no limit.
+	MaxEntries int
+	// MaxBytes is the maximum total size of all entries. Zero means no limit.
+	MaxBytes int64
+	// TTL is how long entries live. Expired entries are treated as missing, and are evicted like any other entry. Zero means entries never expire.
+	TTL time.Duration
+	// SizeOf returns the size of an entry's value in bytes. Defaults to EstimateSize.
+	SizeOf func(value js.Value) int64
+	// AccessBatchSize is the number of buffered access times which triggers a flush on read. Defaults to 100.
+	AccessBatchSize int
+	// AccessFlushInterval is the longest time access times are buffered before a read triggers a flush. Defaults to 10 seconds.
+	AccessFlushInterval time.Duration
+}
+
+// CreateIndex creates the access time index on store, which must use out-of-line keys. Call it in an Upgrader.
+func CreateIndex(store *idb.ObjectStore) (*idb.Index, error) {
+	keyPath, err := safejs.ValueOf(accessedField)
+	if err != nil {
+		return nil, err
+	}
+	return store.CreateIndex(IndexName, safejs.Unsafe(keyPath), idb.IndexOptions{})
+}
+
+// Store is a cache in an existing object store, which must have an index created by CreateIndex
+type Store struct {
+	db        *idb.Database
+	storeName string
+	options   Options
+	now       func() time.Time
+
+	accessMu    sync.Mutex
+	accessed    map[string]pendingAccess // buffered access times, keyed by idbkey.Key.String()
+	lastFlushed time.Time                // zero until the first access is buffered
+}
+
+type pendingAccess struct {
+	key    js.Value
+	millis int64
+}
+
+type meta struct {
+	count int
+	bytes int64
+}
+
+// Wrap returns a Store which caches entries in db's object store named storeName
+func Wrap(db *idb.Database, storeName string, options Options) *Store {
+	if options.SizeOf == nil {
+		options.SizeOf = EstimateSize
+	}
+	if options.AccessBatchSize <= 0 {
+		options.AccessBatchSize = defaultAccessBatchSize
+	}
+	if options.AccessFlushInterval <= 0 {
+		options.AccessFlushInterval = defaultAccessFlushInterval
+	}
+	return &Store{
+		db:        db,
+		storeName: storeName,
+		options:   options,
+		now:       time.Now,
+		accessed:  make(map[string]pendingAccess),
+	}
+}
+
+// Get returns the value for key, or undefined if key is not cached or has expired. Marks the entry as recently used.
+func (s *Store) Get(ctx context.Context, key js.Value) (js.Value, error) {
+	var envelope safejs.Value
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		var err error
+		envelope, err = getEnvelope(ctx, store, key)
+		return err
+	})
+	if err != nil {
+		return js.Value{}, err
+	}
+	now := s.now()
+	if envelope.IsUndefined() || s.expired(envelope, now) {
+		return js.Undefined(), nil
+	}
+	value, err := envelope.Get(valueField)
+	if err != nil {
+		return js.Value{}, err
+	}
+	if err := s.recordAccess(ctx, key, now); err != nil {
+		return js.Value{}, err
+	}
+	return safejs.Unsafe(value), nil
+}
+
+// Put caches value for key, then evicts the least recently used entries until the cache is within its limits
+func (s *Store) Put(ctx context.Context, key, value js.Value) error {
+	size := s.options.SizeOf(value)
+	if s.options.MaxBytes > 0 && size > s.options.MaxBytes {
+		return ErrTooLarge
+	}
+	now := s.now()
+	envelope := map[string]interface{}{
+		valueField:    value,
+		sizeField:     size,
+		accessedField: now.UnixMilli(),
+	}
+	if s.options.TTL > 0 {
+		envelope[expiresField] = now.Add(s.options.TTL).UnixMilli()
+	}
+	jsEnvelope, err := safejs.ValueOf(envelope)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(store *idb.ObjectStore, m *meta) error {
+		if err := removeEntry(ctx, store, key, m); err != nil {
+			return err
+		}
+		if _, err := store.PutKey(key, safejs.Unsafe(jsEnvelope)); err != nil {
+			return err
+		}
+		m.count++
+		m.bytes += size
+		return s.evict(ctx, store, m, key)
+	})
+}
+
+// Delete removes the entry for key
+func (s *Store) Delete(ctx context.Context, key js.Value) error {
+	return s.update(ctx, func(store *idb.ObjectStore, m *meta) error {
+		return removeEntry(ctx, store, key, m)
+	})
+}
+
+// Len returns the number of cached entries, including expired entries not evicted yet
+func (s *Store) Len(ctx context.Context) (int, error) {
+	m, err := s.meta(ctx)
+	return m.count, err
+}
+
+// Size returns the total size of cached entries in bytes, including expired entries not evicted yet
+func (s *Store) Size(ctx context.Context) (int64, error) {
+	m, err := s.meta(ctx)
+	return m.bytes, err
+}
+
+// Flush writes buffered access times
+func (s *Store) Flush(ctx context.Context) error {
+	s.accessMu.Lock()
+	empty := len(s.accessed) == 0
+	s.accessMu.Unlock()
+	if empty {
+		return nil
+	}
+	return s.update(ctx, func(*idb.ObjectStore, *meta) error {
+		return nil
+	})
+}
+
+func (s *Store) meta(ctx context.Context) (meta, error) {
+	var m meta
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		var err error
+		m, err = getMeta(ctx, store)
+		return err
+	})
+	return m, err
+}
+
+// update runs fn in a read-write transaction with the current metadata, after writing buffered access times. Then saves the metadata.
+func (s *Store) update(ctx context.Context, fn func(*idb.ObjectStore, *meta) error) error {
+	accessed := s.takeAccessed()
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		m, err := getMeta(ctx, store)
+		if err != nil {
+			return err
+		}
+		if err := writeAccessed(ctx, store, accessed); err != nil {
+			return err
+		}
+		if err := fn(store, &m); err != nil {
+			return err
+		}
+		return putMeta(store, m)
+	})
+}
+
+// evict deletes the least recently used entries until m is within the cache's limits, except for the entry at keep which was just written.
+// Other entries may share keep's access time or have a later one from another tab's clock, so keep isn't necessarily the newest in the index.
+func (s *Store) evict(ctx context.Context, store *idb.ObjectStore, m *meta, keep js.Value) error {
+	overLimit := func() bool {
+		return (s.options.MaxEntries > 0 && m.count > s.options.MaxEntries) ||
+			(s.options.MaxBytes > 0 && m.bytes > s.options.MaxBytes)
+	}
+	if !overLimit() {
+		return nil
+	}
+	keepKey, err := idb.ParseKey(keep)
+	if err != nil {
+		return err
+	}
+	index, err := store.Index(IndexName)
+	if err != nil {
+		return err
+	}
+	req, err := index.OpenCursor(idb.CursorNext)
+	if err != nil {
+		return err
+	}
+	return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		primaryKey, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		parsedKey, err := idb.ParseKey(primaryKey)
+		if err != nil {
+			return err
+		}
+		if parsedKey.Equal(keepKey) {
+			return nil
+		}
+		envelope, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		if _, err := cursor.Delete(); err != nil {
+			return err
+		}
+		m.count--
+		m.bytes -= envelopeSize(safejs.Safe(envelope))
+		if !overLimit() {
+			return idb.ErrCursorStopIter
+		}
+		return nil
+	})
+}
+
+// recordAccess buffers key's access time, then flushes buffered access times if there are enough of them
+func (s *Store) recordAccess(ctx context.Context, key js.Value, now time.Time) error {
+	parsedKey, err := idb.ParseKey(key)
+	if err != nil {
+		return err
+	}
+	s.accessMu.Lock()
+	if s.lastFlushed.IsZero() {
+		s.lastFlushed = now
+	}
+	s.accessed[parsedKey.String()] = pendingAccess{key: key, millis: now.UnixMilli()}
+	flush := len(s.accessed) >= s.options.AccessBatchSize || now.Sub(s.lastFlushed) >= s.options.AccessFlushInterval
+	s.accessMu.Unlock()
+	if flush {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// takeAccessed returns and clears buffered access times
+func (s *Store) takeAccessed() map[string]pendingAccess {
+	s.accessMu.Lock()
+	defer s.accessMu.Unlock()
+	accessed := s.accessed
+	s.accessed = make(map[string]pendingAccess)
+	s.lastFlushed = s.now()
+	return accessed
+}
+
+func (s *Store) expired(envelope safejs.Value, now time.Time) bool {
+	expires, err := envelope.Get(expiresField)
+	if err != nil || expires.Type() != safejs.TypeNumber {
+		return false
+	}
+	millis, err := expires.Float()
+	return err == nil && millis <= float64(now.UnixMilli())
+}
+
+// writeAccessed updates the access time of each entry which still exists
+func writeAccessed(ctx context.Context, store *idb.ObjectStore, accessed map[string]pendingAccess) error {
+	for _, access := range accessed {
+		envelope, err := getEnvelope(ctx, store, access.key)
+		if err != nil {
+			return err
+		}
+		if envelope.IsUndefined() {
+			continue
+		}
+		if err := envelope.Set(accessedField, access.millis); err != nil {
+			return err
+		}
+		if _, err := store.PutKey(access.key, safejs.Unsafe(envelope)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEntry deletes the entry for key if it exists, and subtracts it from m
+func removeEntry(ctx context.Context, store *idb.ObjectStore, key js.Value, m *meta) error {
+	envelope, err := getEnvelope(ctx, store, key)
+	if err != nil || envelope.IsUndefined() {
+		return err
+	}
+	if _, err := store.Delete(key); err != nil {
+		return err
+	}
+	m.count--
+	m.bytes -= envelopeSize(envelope)
+	return nil
+}
+
+func getEnvelope(ctx context.Context, store *idb.ObjectStore, key js.Value) (safejs.Value, error) {
+	req, err := store.Get(key)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	envelope, err := req.Await(ctx)
+	return safejs.Safe(envelope), err
+}
+
+func envelopeSize(envelope safejs.Value) int64 {
+	size, err := envelope.Get(sizeField)
+	if err != nil {
+		return 0
+	}
+	sizeFloat, err := size.Float()
+	if err != nil {
+		return 0
+	}
+	return int64(sizeFloat)
+}
+
+func getMeta(ctx context.Context, store *idb.ObjectStore) (meta, error) {
+	jsMeta, err := getEnvelope(ctx, store, safejs.Unsafe(jsMetaKey))
+	if err != nil || jsMeta.IsUndefined() {
+		return meta{}, err
+	}
+	count, err := jsMeta.Get("count")
+	if err != nil {
+		return meta{}, err
+	}
+	countInt, err := count.Int()
+	if err != nil {
+		return meta{}, err
+	}
+	return meta{count: countInt, bytes: envelopeSize(jsMeta)}, nil
+}
+
+func putMeta(store *idb.ObjectStore, m meta) error {
+	jsMeta, err := safejs.ValueOf(map[string]interface{}{
+		"count":   m.count,
+		sizeField: m.bytes,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = store.PutKey(safejs.Unsafe(jsMetaKey), safejs.Unsafe(jsMeta))
+	return err
+}
@@ -0,0 +1,188 @@
+//go:build js && wasm
+// +build js,wasm
+
+package lru
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance() {
+	c.now = c.now.Add(time.Second)
+}
+
+func testStore(tb testing.TB, options Options) (*Store, *testClock) {
+	tb.Helper()
+	db := idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		store, err := db.CreateObjectStore("cache", idb.ObjectStoreOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = CreateIndex(store)
+		return err
+	})
+	store := Wrap(db, "cache", options)
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store.now = clock.Now
+	return store, clock
+}
+
+func getString(tb testing.TB, store *Store, key string) string {
+	tb.Helper()
+	value, err := store.Get(context.Background(), js.ValueOf(key))
+	assert.NoError(tb, err)
+	if value.IsUndefined() {
+		return ""
+	}
+	return value.String()
+}
+
+func TestStorePutGet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, _ := testStore(t, Options{})
+
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("value a")))
+	assert.Equal(t, "value a", getString(t, store, "a"))
+	assert.Equal(t, "", getString(t, store, "missing"))
+
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("new")))
+	assert.Equal(t, "new", getString(t, store, "a"))
+	length, err := store.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, length)
+	size, err := store.Size(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), size)
+
+	assert.NoError(t, store.Delete(ctx, js.ValueOf("a")))
+	assert.Equal(t, "", getString(t, store, "a"))
+	length, err = store.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, length)
+}
+
+func TestStoreEvictMaxEntries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, clock := testStore(t, Options{MaxEntries: 2, AccessBatchSize: 1})
+
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("a")))
+	clock.Advance()
+	assert.NoError(t, store.Put(ctx, js.ValueOf("b"), js.ValueOf("b")))
+	clock.Advance()
+	assert.Equal(t, "a", getString(t, store, "a")) // a is now more recently used than b
+	clock.Advance()
+	assert.NoError(t, store.Put(ctx, js.ValueOf("c"), js.ValueOf("c")))
+
+	assert.Equal(t, "a", getString(t, store, "a"))
+	assert.Equal(t, "", getString(t, store, "b"))
+	assert.Equal(t, "c", getString(t, store, "c"))
+	length, err := store.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, length)
+}
+
+func TestStoreEvictKeepsNewEntry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, clock := testStore(t, Options{MaxEntries: 1})
+
+	// a and b have the same access time, so b sorts after a in the index
+	assert.NoError(t, store.Put(ctx, js.ValueOf("b"), js.ValueOf("b")))
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("a")))
+	assert.Equal(t, "a", getString(t, store, "a"))
+	assert.Equal(t, "", getString(t, store, "b"))
+
+	// c has an earlier access time than a, like from another tab with a skewed clock
+	clock.now = clock.now.Add(-time.Minute)
+	assert.NoError(t, store.Put(ctx, js.ValueOf("c"), js.ValueOf("c")))
+	assert.Equal(t, "c", getString(t, store, "c"))
+	assert.Equal(t, "", getString(t, store, "a"))
+	length, err := store.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, length)
+}
+
+func TestStoreEvictMaxBytes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, clock := testStore(t, Options{
+		MaxBytes: 10,
+		SizeOf: func(value js.Value) int64 {
+			return int64(value.Length())
+		},
+	})
+	bytes := func(n int) js.Value {
+		return js.Global().Get("Uint8Array").New(n)
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(t, store.Put(ctx, js.ValueOf(key), bytes(4)))
+		clock.Advance()
+	}
+	size, err := store.Size(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), size)
+	value, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.IsUndefined())
+
+	assert.ErrorIs(t, store.Put(ctx, js.ValueOf("d"), bytes(11)), ErrTooLarge)
+}
+
+func TestStoreTTL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, clock := testStore(t, Options{TTL: 2 * time.Second})
+
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("a")))
+	clock.Advance()
+	assert.Equal(t, "a", getString(t, store, "a"))
+	clock.Advance()
+	assert.Equal(t, "", getString(t, store, "a"))
+}
+
+func TestStoreFlush(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, _ := testStore(t, Options{})
+
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("a")))
+	assert.Equal(t, "a", getString(t, store, "a"))
+	assert.Equal(t, 1, len(store.accessed))
+	assert.NoError(t, store.Flush(ctx))
+	assert.Equal(t, 0, len(store.accessed))
+	assert.NoError(t, store.Flush(ctx))
+}
+
+func TestStoreFlushInterval(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store, clock := testStore(t, Options{AccessFlushInterval: 2 * time.Second})
+
+	assert.NoError(t, store.Put(ctx, js.ValueOf("a"), js.ValueOf("a")))
+	assert.Equal(t, "a", getString(t, store, "a"))
+	assert.Equal(t, 1, len(store.accessed))
+	clock.Advance()
+	assert.Equal(t, "a", getString(t, store, "a"))
+	assert.Equal(t, 1, len(store.accessed))
+	clock.Advance()
+	assert.Equal(t, "a", getString(t, store, "a"))
+	assert.Equal(t, 0, len(store.accessed))
+}
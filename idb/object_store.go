@@ -48,6 +48,22 @@ func (o *ObjectStore) Name() (string, error) {
 	return name.String()
 }
 
+// SetName renames this object store. Only allowed in a version change transaction, like inside an Upgrader.
+func (o *ObjectStore) SetName(name string) error {
+	if err := o.base.txn.requireVersionChange(); err != nil {
+		return err
+	}
+	oldName, err := o.Name()
+	if err != nil {
+		return err
+	}
+	if err := o.base.jsObjectStore.Set("name", name); err != nil {
+		return tryAsDOMException(err)
+	}
+	o.base.txn.renameObjectStore(o, oldName, name)
+	return nil
+}
+
 // Transaction returns the Transaction object to which this object store belongs.
 func (o *ObjectStore) Transaction() (*Transaction, error) {
 	if o.base.txn == (*Transaction)(nil) {
@@ -72,6 +88,7 @@ func (o *ObjectStore) Add(value js.Value) (*AckRequest, error) {
 		return nil, tryAsDOMException(err)
 	}
 	req := wrapRequest(o.base.txn, reqValue)
+	o.base.txn.trackRequest(o.base.jsObjectStore, req)
 	return newAckRequest(req), nil
 }
 
@@ -82,6 +99,7 @@ func (o *ObjectStore) AddKey(key, value js.Value) (*AckRequest, error) {
 		return nil, tryAsDOMException(err)
 	}
 	req := wrapRequest(o.base.txn, reqValue)
+	o.base.txn.trackRequest(o.base.jsObjectStore, req)
 	return newAckRequest(req), nil
 }
 
@@ -91,6 +109,7 @@ func (o *ObjectStore) Clear() (*AckRequest, error) {
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
+	o.base.txn.trackAllKeys(o.base.jsObjectStore)
 	req := wrapRequest(o.base.txn, reqValue)
 	return newAckRequest(req), nil
 }
@@ -128,6 +147,22 @@ func (o *ObjectStore) Delete(key js.Value) (*AckRequest, error) {
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
+	o.base.txn.trackKey(o.base.jsObjectStore, safejs.Safe(key))
+	req := wrapRequest(o.base.txn, reqValue)
+	return newAckRequest(req), nil
+}
+
+// DeleteRange returns an AckRequest, and, in a separate thread, deletes every record in the store inside keyRange.
+func (o *ObjectStore) DeleteRange(keyRange *KeyRange) (*AckRequest, error) {
+	jsKeyRange, err := keyRange.jsValue()
+	if err != nil {
+		return nil, err
+	}
+	reqValue, err := o.base.jsObjectStore.Call("delete", jsKeyRange)
+	if err != nil {
+		return nil, tryAsDOMException(err)
+	}
+	o.base.txn.trackAllKeys(o.base.jsObjectStore)
 	req := wrapRequest(o.base.txn, reqValue)
 	return newAckRequest(req), nil
 }
@@ -173,7 +208,9 @@ func (o *ObjectStore) Put(value js.Value) (*Request, error) {
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
-	return wrapRequest(o.base.txn, reqValue), nil
+	req := wrapRequest(o.base.txn, reqValue)
+	o.base.txn.trackRequest(o.base.jsObjectStore, req)
+	return req, nil
 }
 
 // PutKey is the same as Put, but includes the key to use to identify the record.
@@ -182,7 +219,9 @@ func (o *ObjectStore) PutKey(key, value js.Value) (*Request, error) {
 	if err != nil {
 		return nil, tryAsDOMException(err)
 	}
-	return wrapRequest(o.base.txn, reqValue), nil
+	req := wrapRequest(o.base.txn, reqValue)
+	o.base.txn.trackRequest(o.base.jsObjectStore, req)
+	return req, nil
 }
 
 // OpenCursor returns a CursorWithValueRequest, and, in a separate thread, returns a new CursorWithValue. Used for iterating through an object store by primary key with a cursor.
@@ -63,6 +63,43 @@ func TestObjectStoreName(t *testing.T) {
 	assert.Equal(t, "mystore", name)
 }
 
+func TestObjectStoreSetName(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, store.SetName("created"))
+	})
+	db = upgradeTestDB(t, db, func(db *Database, oldVersion, newVersion uint) error {
+		txn, err := db.UpgradeTransaction()
+		if err != nil {
+			return err
+		}
+		store, err := txn.ObjectStore("created")
+		if err != nil {
+			return err
+		}
+		if err := store.SetName("renamed"); err != nil {
+			return err
+		}
+		renamedStore, err := txn.ObjectStore("renamed")
+		assert.NoError(t, err)
+		assert.Equal(t, store, renamedStore)
+		_, err = txn.ObjectStore("created")
+		assert.Error(t, err)
+		return nil
+	})
+	names, err := db.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"renamed"}, names)
+
+	txn, err := db.Transaction(TransactionReadWrite, "renamed")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("renamed")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, store.SetName("other"), errNotVersionChange)
+}
+
 func TestObjectStoreAutoIncrement(t *testing.T) {
 	t.Parallel()
 	db := testDB(t, func(db *Database) {
@@ -251,6 +288,32 @@ func TestObjectStoreDelete(t *testing.T) {
 	assert.Equal(t, js.Undefined(), result)
 }
 
+func TestObjectStoreDeleteRange(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		_, err = store.AddKey(js.ValueOf(key), js.ValueOf("some value"))
+		assert.NoError(t, err)
+	}
+
+	keyRange, err := NewKeyRangeBound(js.ValueOf("b"), js.ValueOf("c"), false, false)
+	assert.NoError(t, err)
+	_, err = store.DeleteRange(keyRange)
+	assert.NoError(t, err)
+	req, err := store.GetAllKeys()
+	assert.NoError(t, err)
+	result, err := req.Await(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []js.Value{js.ValueOf("a"), js.ValueOf("d")}, result)
+}
+
 func TestObjectStoreDeleteIndex(t *testing.T) {
 	t.Parallel()
 	testDB(t, func(db *Database) {
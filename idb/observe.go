@@ -0,0 +1,214 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"time"
+)
+
+// observeDebounce is how long to wait after a change for more changes before re-running an observed query
+const observeDebounce = 10 * time.Millisecond
+
+// ObservedRecords contains the records inside an observed key range, sorted by the cursor's key
+type ObservedRecords struct {
+	PrimaryKeys []js.Value
+	Values      []js.Value
+	// Err is set if the query failed. The next change will try again.
+	Err error
+}
+
+// Observe returns a channel of the records inside keyRange. Sends the current records first, then re-runs the query and sends fresh records after each write that overlaps keyRange. A nil keyRange observes all records.
+// Writes are detected from readwrite transactions in this tab, and from connections in other tabs and workers with their change feed enabled (see Database.EnableChangeFeed). Bursts of writes are debounced into a single query.
+//
+// The channel is closed when ctx is canceled.
+func (o *ObjectStore) Observe(ctx context.Context, keyRange *KeyRange) (<-chan ObservedRecords, error) {
+	txn, err := o.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	storeName, err := o.Name()
+	if err != nil {
+		return nil, err
+	}
+	matches := func(event ChangeEvent) bool {
+		if event.AllKeys || keyRange == nil {
+			return true
+		}
+		for _, key := range event.Keys {
+			if keyRange.keyRange.Includes(key) {
+				return true
+			}
+		}
+		return false
+	}
+	openCursor := func(txn *Transaction) (*CursorWithValueRequest, error) {
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return nil, err
+		}
+		if keyRange == nil {
+			return store.OpenCursor(CursorNext)
+		}
+		return store.OpenCursorRange(keyRange, CursorNext)
+	}
+	return observe(ctx, txn.db, storeName, matches, openCursor)
+}
+
+// Observe is the same as ObjectStore.Observe, but observes a range of index keys.
+// Since any write to the object store could move a record into or out of the index's key range, the query re-runs after every write to the object store.
+func (i *Index) Observe(ctx context.Context, keyRange *KeyRange) (<-chan ObservedRecords, error) {
+	store, err := i.ObjectStore()
+	if err != nil {
+		return nil, err
+	}
+	txn, err := store.Transaction()
+	if err != nil {
+		return nil, err
+	}
+	storeName, err := store.Name()
+	if err != nil {
+		return nil, err
+	}
+	indexName, err := i.Name()
+	if err != nil {
+		return nil, err
+	}
+	matches := func(ChangeEvent) bool {
+		return true
+	}
+	openCursor := func(txn *Transaction) (*CursorWithValueRequest, error) {
+		store, err := txn.ObjectStore(storeName)
+		if err != nil {
+			return nil, err
+		}
+		index, err := store.Index(indexName)
+		if err != nil {
+			return nil, err
+		}
+		if keyRange == nil {
+			return index.OpenCursor(CursorNext)
+		}
+		return index.OpenCursorRange(keyRange, CursorNext)
+	}
+	return observe(ctx, txn.db, storeName, matches, openCursor)
+}
+
+// observe runs openCursor's query in a new transaction, then again after each change to storeName for which matches returns true
+func observe(
+	ctx context.Context,
+	db *Database,
+	storeName string,
+	matches func(ChangeEvent) bool,
+	openCursor func(*Transaction) (*CursorWithValueRequest, error),
+) (<-chan ObservedRecords, error) {
+	dbName, err := db.Name()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	changed := make(chan struct{}, 1)
+	onChange := func(event ChangeEvent) {
+		if event.StoreName != storeName || !matches(event) {
+			return
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	remoteChanges, err := db.watch(ctx, true, []string{storeName}) // this process's changes come from localChanges
+	switch {
+	case errors.Is(err, errBroadcastChannelUnsupported):
+		// only observe changes from this tab
+	case err != nil:
+		cancel()
+		return nil, err
+	default:
+		go func() {
+			for event := range remoteChanges {
+				onChange(event)
+			}
+		}()
+	}
+	removeLocal := localChanges.add(dbName, func(events []ChangeEvent) {
+		for _, event := range events {
+			onChange(event)
+		}
+	})
+
+	results := make(chan ObservedRecords)
+	go func() {
+		defer close(results)
+		defer removeLocal()
+		defer cancel()
+		for {
+			select {
+			case results <- queryRecords(ctx, db, storeName, openCursor):
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+			if !debounce(ctx, changed, observeDebounce) {
+				return
+			}
+		}
+	}()
+	return results, nil
+}
+
+// debounce waits until no values are received from changed for the wait duration. Returns false if ctx is canceled first.
+func debounce(ctx context.Context, changed <-chan struct{}, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-changed:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(wait)
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func queryRecords(ctx context.Context, db *Database, storeName string, openCursor func(*Transaction) (*CursorWithValueRequest, error)) ObservedRecords {
+	txn, err := db.Transaction(TransactionReadOnly, storeName)
+	if err != nil {
+		return ObservedRecords{Err: err}
+	}
+	req, err := openCursor(txn)
+	if err != nil {
+		return ObservedRecords{Err: err}
+	}
+	var records ObservedRecords
+	err = req.Iter(ctx, func(cursor *CursorWithValue) error {
+		primaryKey, err := cursor.PrimaryKey()
+		if err != nil {
+			return err
+		}
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		records.PrimaryKeys = append(records.PrimaryKeys, primaryKey)
+		records.Values = append(records.Values, value)
+		return nil
+	})
+	if err != nil {
+		return ObservedRecords{Err: err}
+	}
+	return records
+}
@@ -0,0 +1,194 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func receiveRecords(tb testing.TB, results <-chan ObservedRecords) ObservedRecords {
+	tb.Helper()
+	select {
+	case records := <-results:
+		assert.NoError(tb, records.Err)
+		return records
+	case <-time.After(5 * time.Second):
+		tb.Fatal("Timed out waiting for observed records")
+		return ObservedRecords{}
+	}
+}
+
+func putRecords(tb testing.TB, db *Database, records map[string]interface{}) {
+	tb.Helper()
+	txn, err := db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(tb, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(tb, err)
+	for key, value := range records {
+		_, err := store.PutKey(js.ValueOf(key), js.ValueOf(value))
+		assert.NoError(tb, err)
+	}
+	assert.NoError(tb, txn.Await(context.Background()))
+}
+
+func TestObjectStoreObserve(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	putRecords(t, db, map[string]interface{}{"a": 1, "c": 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	keyRange, err := NewKeyRangeBound(js.ValueOf("b"), js.ValueOf("d"), false, false)
+	assert.NoError(t, err)
+	results, err := store.Observe(ctx, keyRange)
+	assert.NoError(t, err)
+
+	records := receiveRecords(t, results)
+	assert.Equal(t, []js.Value{js.ValueOf("c")}, records.PrimaryKeys)
+	assert.Equal(t, []js.Value{js.ValueOf(3)}, records.Values)
+
+	putRecords(t, db, map[string]interface{}{"z": 26}) // outside the key range
+	putRecords(t, db, map[string]interface{}{"b": 2})
+	records = receiveRecords(t, results)
+	assert.Equal(t, []js.Value{js.ValueOf("b"), js.ValueOf("c")}, records.PrimaryKeys)
+	assert.Equal(t, []js.Value{js.ValueOf(2), js.ValueOf(3)}, records.Values)
+
+	cancel()
+	_, open := <-results
+	assert.Equal(t, false, open)
+}
+
+func TestObjectStoreObserveClear(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	putRecords(t, db, map[string]interface{}{"a": 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	results, err := store.Observe(ctx, nil)
+	assert.NoError(t, err)
+	records := receiveRecords(t, results)
+	assert.Equal(t, []js.Value{js.ValueOf("a")}, records.PrimaryKeys)
+
+	txn, err = db.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	store, err = txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.Clear()
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+
+	records = receiveRecords(t, results)
+	assert.Zero(t, len(records.PrimaryKeys))
+}
+
+func TestIndexObserve(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		store, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = store.CreateIndex("myindex", js.ValueOf("primary"), IndexOptions{})
+		assert.NoError(t, err)
+	})
+	putRecords(t, db, map[string]interface{}{
+		"a": map[string]interface{}{"primary": "x"},
+		"b": map[string]interface{}{"primary": "y"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	index, err := store.Index("myindex")
+	assert.NoError(t, err)
+	keyRange, err := NewKeyRangeOnly(js.ValueOf("x"))
+	assert.NoError(t, err)
+	results, err := index.Observe(ctx, keyRange)
+	assert.NoError(t, err)
+	records := receiveRecords(t, results)
+	assert.Equal(t, []js.Value{js.ValueOf("a")}, records.PrimaryKeys)
+
+	// moving a record into the index's key range re-runs the query
+	putRecords(t, db, map[string]interface{}{"b": map[string]interface{}{"primary": "x"}})
+	records = receiveRecords(t, results)
+	assert.Equal(t, []js.Value{js.ValueOf("a"), js.ValueOf("b")}, records.PrimaryKeys)
+}
+
+func TestObjectStoreObserveWithChangeFeed(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	assert.NoError(t, db.EnableChangeFeed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	txn, err := db.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	results, err := store.Observe(ctx, nil)
+	assert.NoError(t, err)
+	records := receiveRecords(t, results)
+	assert.Zero(t, len(records.PrimaryKeys))
+
+	putRecords(t, db, map[string]interface{}{"a": 1})
+	records = receiveRecords(t, results)
+	assert.Equal(t, []js.Value{js.ValueOf("a")}, records.PrimaryKeys)
+
+	// this tab's writes are only delivered once, not again from the change feed
+	select {
+	case records := <-results:
+		t.Errorf("Unexpected records after a single write: %v", records)
+	case <-time.After(10 * observeDebounce):
+	}
+}
+
+func TestObserveOnlyTracksObservedDatabase(t *testing.T) {
+	t.Parallel()
+	createStore := func(db *Database) {
+		_, err := db.CreateObjectStore("mystore", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	}
+	observedDB := testDB(t, createStore)
+	otherDB := testDB(t, createStore)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	txn, err := observedDB.Transaction(TransactionReadOnly, "mystore")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("mystore")
+	assert.NoError(t, err)
+	_, err = store.Observe(ctx, nil)
+	assert.NoError(t, err)
+
+	txn, err = observedDB.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	assert.NotZero(t, txn.changes)
+	txn, err = otherDB.Transaction(TransactionReadWrite, "mystore")
+	assert.NoError(t, err)
+	assert.Zero(t, txn.changes)
+}
@@ -85,6 +85,11 @@ func openDBUpgradeNeeded(req *Request, upgrader Upgrader, args []safejs.Value) e
 		return err
 	}
 	db := wrapDatabase(jsDatabase)
+	jsTxn, err := req.jsRequest.Get("transaction")
+	if err != nil {
+		return err
+	}
+	db.upgradeTxn = wrapTransaction(db, jsTxn)
 	oldVersionValue, err := event.Get("oldVersion")
 	if err != nil {
 		return err
@@ -104,7 +109,9 @@ func openDBUpgradeNeeded(req *Request, upgrader Upgrader, args []safejs.Value) e
 	if oldVersion < 0 || newVersion < 0 {
 		return fmt.Errorf("Unexpected negative oldVersion or newVersion: %d, %d", oldVersion, newVersion)
 	}
-	return upgrader(db, uint(oldVersion), uint(newVersion))
+	err = upgrader(db, uint(oldVersion), uint(newVersion))
+	db.upgradeTxn = nil // the upgrade transaction finishes after the upgrader returns
+	return err
 }
 
 // Result returns the result of the request. If the request failed and the result is not available, an error is returned.
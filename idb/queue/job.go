@@ -0,0 +1,117 @@
+//go:build js && wasm
+// +build js,wasm
+
+package queue
+
+import (
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	idField         = "id"
+	rankField       = "rank"
+	priorityField   = "priority"
+	payloadField    = "payload"
+	attemptsField   = "attempts"
+	enqueuedAtField = "enqueuedAt"
+	visibleAtField  = "visibleAt"
+	claimField      = "claim"
+)
+
+// Job is a unit of work in a Queue
+type Job struct {
+	// ID is the job's key, assigned in order of Enqueue
+	ID int64
+	// Priority orders jobs in the queue. Higher priorities are dequeued first.
+	Priority int
+	// Payload is the job's value passed to Enqueue
+	Payload js.Value
+	// Attempts is the number of times the job has been dequeued, including the current attempt
+	Attempts int
+	// EnqueuedAt is when the job was first enqueued
+	EnqueuedAt time.Time
+
+	claim string // identifies the Dequeue call which claimed this job
+}
+
+// record is a job as stored in the queue's object store
+type record struct {
+	value     safejs.Value
+	job       *Job
+	visibleAt int64 // milliseconds since the Unix epoch when the job can be dequeued
+}
+
+func parseRecord(value js.Value) (record, error) {
+	jsValue := safejs.Safe(value)
+	job := &Job{}
+	r := record{value: jsValue, job: job}
+	var err error
+	if job.ID, err = intField(jsValue, idField); err != nil {
+		return record{}, err
+	}
+	priority, err := intField(jsValue, priorityField)
+	if err != nil {
+		return record{}, err
+	}
+	job.Priority = int(priority)
+	payload, err := jsValue.Get(payloadField)
+	if err != nil {
+		return record{}, err
+	}
+	job.Payload = safejs.Unsafe(payload)
+	attempts, err := intField(jsValue, attemptsField)
+	if err != nil {
+		return record{}, err
+	}
+	job.Attempts = int(attempts)
+	enqueuedAt, err := intField(jsValue, enqueuedAtField)
+	if err != nil {
+		return record{}, err
+	}
+	job.EnqueuedAt = time.UnixMilli(enqueuedAt)
+	if r.visibleAt, err = intField(jsValue, visibleAtField); err != nil {
+		return record{}, err
+	}
+	claim, err := jsValue.Get(claimField)
+	if err != nil {
+		return record{}, err
+	}
+	if claim.Type() == safejs.TypeString {
+		if job.claim, err = claim.String(); err != nil {
+			return record{}, err
+		}
+	}
+	return r, nil
+}
+
+// update sets the record's attempts, visibility, and claim
+func (r record) update(attempts int, visibleAt int64, claim string) error {
+	if err := r.value.Set(attemptsField, attempts); err != nil {
+		return err
+	}
+	if err := r.value.Set(visibleAtField, visibleAt); err != nil {
+		return err
+	}
+	var claimValue interface{}
+	if claim != "" {
+		claimValue = claim
+	}
+	if err := r.value.Set(claimField, claimValue); err != nil {
+		return err
+	}
+	r.job.Attempts = attempts
+	r.job.claim = claim
+	return nil
+}
+
+func intField(value safejs.Value, name string) (int64, error) {
+	field, err := value.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := field.Float()
+	return int64(n), err
+}
@@ -0,0 +1,392 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package queue stores a durable job queue in IndexedDB, which survives reloads and is shared by every tab.
+//
+// Jobs are dequeued highest priority first, then in the order they were enqueued. Dequeue claims a job for a visibility timeout, after which it's dequeued again unless acknowledged with Ack. Nack retries a job with backoff, until it runs out of attempts and moves to a dead-letter store.
+// Each operation runs in a single read-write transaction, so claims are atomic across tabs. Options.Lock additionally serializes operations with a Web Lock.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// deadLetterSuffix is appended to the queue's name to name its dead-letter store
+	deadLetterSuffix = "-dead"
+	// rankIndexName is the name of the index ordering jobs by priority, then ID
+	rankIndexName = "byRank"
+
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultMaxAttempts       = 5
+	maxBackoff               = time.Hour
+)
+
+var (
+	// ErrEmpty is returned by Dequeue and Peek when no jobs are ready
+	ErrEmpty = errors.New("queue: no jobs ready")
+	// ErrClaimExpired is returned by Ack and Nack when the job's visibility timeout expired and the job was claimed again or removed
+	ErrClaimExpired = errors.New("queue: job claim expired")
+)
+
+// Options contains all available options for a Queue
+type Options struct {
+	// VisibilityTimeout is how long a dequeued job is hidden before it can be dequeued again. Defaults to 30 seconds.
+	VisibilityTimeout time.Duration
+	// MaxAttempts is the number of times a job can be dequeued before it moves to the dead-letter store. Defaults to 5.
+	MaxAttempts int
+	// Backoff returns how long to wait before retrying a job nacked after the given attempt. Defaults to DefaultBackoff.
+	Backoff func(attempt int) time.Duration
+	// Lock serializes Dequeue, Ack, and Nack across tabs with a Web Lock, in addition to their transactions
+	Lock bool
+}
+
+// DefaultBackoff doubles the delay after every attempt, starting at 1 second, up to 1 hour
+func DefaultBackoff(attempt int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// CreateStores creates the object stores for a queue named name. Call it in an Upgrader.
+func CreateStores(db *idb.Database, name string) error {
+	keyPath, err := safejs.ValueOf(idField)
+	if err != nil {
+		return err
+	}
+	store, err := db.CreateObjectStore(name, idb.ObjectStoreOptions{
+		KeyPath:       safejs.Unsafe(keyPath),
+		AutoIncrement: true,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = store.CreateCompoundIndex(rankIndexName, []string{rankField, idField}, idb.IndexOptions{})
+	if err != nil {
+		return err
+	}
+	_, err = db.CreateObjectStore(name+deadLetterSuffix, idb.ObjectStoreOptions{
+		KeyPath: safejs.Unsafe(keyPath),
+	})
+	return err
+}
+
+// Queue is a job queue in object stores created by CreateStores
+type Queue struct {
+	db       *idb.Database
+	name     string
+	deadName string
+	options  Options
+	now      func() time.Time
+}
+
+// Wrap returns the Queue named name in db
+func Wrap(db *idb.Database, name string, options Options) *Queue {
+	if options.VisibilityTimeout <= 0 {
+		options.VisibilityTimeout = defaultVisibilityTimeout
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+	if options.Backoff == nil {
+		options.Backoff = DefaultBackoff
+	}
+	return &Queue{
+		db:       db,
+		name:     name,
+		deadName: name + deadLetterSuffix,
+		options:  options,
+		now:      time.Now,
+	}
+}
+
+// run runs fn in a new transaction over the queue and dead-letter stores
+func (q *Queue) run(ctx context.Context, mode idb.TransactionMode, fn func(store, dead *idb.ObjectStore) error) error {
+	return txnutil.Run(ctx, q.db, mode, []string{q.name, q.deadName}, func(txn *idb.Transaction) error {
+		store, err := txn.ObjectStore(q.name)
+		if err != nil {
+			return err
+		}
+		dead, err := txn.ObjectStore(q.deadName)
+		if err != nil {
+			return err
+		}
+		return fn(store, dead)
+	})
+}
+
+// runLocked is the same as run, but holds the queue's Web Lock if Options.Lock is set
+func (q *Queue) runLocked(ctx context.Context, fn func(store, dead *idb.ObjectStore) error) error {
+	if !q.options.Lock {
+		return q.run(ctx, idb.TransactionReadWrite, fn)
+	}
+	return q.db.WithLock(ctx, "queue:"+q.name, idb.LockExclusive, func(ctx context.Context) error {
+		return q.run(ctx, idb.TransactionReadWrite, fn)
+	})
+}
+
+// Enqueue adds a job with payload to the queue. Higher priorities are dequeued first. Returns the new job's ID.
+func (q *Queue) Enqueue(ctx context.Context, payload js.Value, priority int) (int64, error) {
+	now := q.now().UnixMilli()
+	value, err := safejs.ValueOf(map[string]interface{}{
+		rankField:       -priority,
+		priorityField:   priority,
+		payloadField:    payload,
+		attemptsField:   0,
+		enqueuedAtField: now,
+		visibleAtField:  now,
+		claimField:      nil,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = q.run(ctx, idb.TransactionReadWrite, func(store, _ *idb.ObjectStore) error {
+		req, err := store.Put(safejs.Unsafe(value)) // value has no ID yet, so Put always adds a new job
+		if err != nil {
+			return err
+		}
+		key, err := req.Await(ctx)
+		if err != nil {
+			return err
+		}
+		keyFloat, err := safejs.Safe(key).Float()
+		id = int64(keyFloat)
+		return err
+	})
+	return id, err
+}
+
+// Dequeue claims the next ready job, which is hidden from other calls to Dequeue for Options.VisibilityTimeout. Returns ErrEmpty if no jobs are ready.
+// Call Ack once the job is done, or Nack to retry it. Ready jobs which ran out of attempts move to the dead-letter store instead.
+func (q *Queue) Dequeue(ctx context.Context) (*Job, error) {
+	claim, err := newClaim()
+	if err != nil {
+		return nil, err
+	}
+	var job *Job
+	err = q.runLocked(ctx, func(store, dead *idb.ObjectStore) error {
+		now := q.now()
+		return q.iterReady(ctx, store, now, func(cursor *idb.CursorWithValue, r record) error {
+			if r.job.Attempts >= q.options.MaxAttempts {
+				return moveToDead(cursor, dead, r)
+			}
+			visibleAt := now.Add(q.options.VisibilityTimeout).UnixMilli()
+			if err := r.update(r.job.Attempts+1, visibleAt, claim); err != nil {
+				return err
+			}
+			if _, err := cursor.Update(safejs.Unsafe(r.value)); err != nil {
+				return err
+			}
+			job = r.job
+			return idb.ErrCursorStopIter
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrEmpty
+	}
+	return job, nil
+}
+
+// Ack removes a dequeued job from the queue. Returns ErrClaimExpired if the job's visibility timeout expired and it was dequeued again.
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	return q.runLocked(ctx, func(store, _ *idb.ObjectStore) error {
+		if _, err := getClaimed(ctx, store, job); err != nil {
+			return err
+		}
+		_, err := store.Delete(jobKey(job))
+		return err
+	})
+}
+
+// Nack releases a dequeued job to be retried after Options.Backoff. If the job ran out of attempts, it moves to the dead-letter store instead.
+// Returns ErrClaimExpired if the job's visibility timeout expired and it was dequeued again.
+func (q *Queue) Nack(ctx context.Context, job *Job) error {
+	return q.runLocked(ctx, func(store, dead *idb.ObjectStore) error {
+		r, err := getClaimed(ctx, store, job)
+		if err != nil {
+			return err
+		}
+		if r.job.Attempts >= q.options.MaxAttempts {
+			if err := r.update(r.job.Attempts, r.visibleAt, ""); err != nil {
+				return err
+			}
+			if _, err := dead.Put(safejs.Unsafe(r.value)); err != nil {
+				return err
+			}
+			_, err := store.Delete(jobKey(job))
+			return err
+		}
+		visibleAt := q.now().Add(q.options.Backoff(r.job.Attempts)).UnixMilli()
+		if err := r.update(r.job.Attempts, visibleAt, ""); err != nil {
+			return err
+		}
+		_, err = store.Put(safejs.Unsafe(r.value))
+		return err
+	})
+}
+
+// Len returns the number of jobs in the queue, including claimed jobs and jobs waiting to retry
+func (q *Queue) Len(ctx context.Context) (int, error) {
+	return q.count(ctx, q.name)
+}
+
+// DeadLen returns the number of jobs in the dead-letter store
+func (q *Queue) DeadLen(ctx context.Context) (int, error) {
+	return q.count(ctx, q.deadName)
+}
+
+func (q *Queue) count(ctx context.Context, storeName string) (int, error) {
+	var count uint
+	err := q.run(ctx, idb.TransactionReadOnly, func(store, dead *idb.ObjectStore) error {
+		if storeName == q.deadName {
+			store = dead
+		}
+		req, err := store.Count()
+		if err != nil {
+			return err
+		}
+		count, err = req.Await(ctx)
+		return err
+	})
+	return int(count), err
+}
+
+// Peek returns the next ready job without claiming it. Returns ErrEmpty if no jobs are ready.
+func (q *Queue) Peek(ctx context.Context) (*Job, error) {
+	var job *Job
+	err := q.run(ctx, idb.TransactionReadOnly, func(store, _ *idb.ObjectStore) error {
+		return q.iterReady(ctx, store, q.now(), func(_ *idb.CursorWithValue, r record) error {
+			if r.job.Attempts >= q.options.MaxAttempts {
+				return nil // moves to the dead-letter store on the next Dequeue
+			}
+			job = r.job
+			return idb.ErrCursorStopIter
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrEmpty
+	}
+	return job, nil
+}
+
+// DeadLetters returns every job in the dead-letter store, in ID order
+func (q *Queue) DeadLetters(ctx context.Context) ([]*Job, error) {
+	var jobs []*Job
+	err := q.run(ctx, idb.TransactionReadOnly, func(_, dead *idb.ObjectStore) error {
+		req, err := dead.OpenCursor(idb.CursorNext)
+		if err != nil {
+			return err
+		}
+		return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			r, err := parseRecord(value)
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, r.job)
+			return nil
+		})
+	})
+	return jobs, err
+}
+
+// iterReady calls fn with each job visible at now, highest priority first, then in ID order
+func (q *Queue) iterReady(ctx context.Context, store *idb.ObjectStore, now time.Time, fn func(*idb.CursorWithValue, record) error) error {
+	index, err := store.Index(rankIndexName)
+	if err != nil {
+		return err
+	}
+	req, err := index.OpenCursor(idb.CursorNext)
+	if err != nil {
+		return err
+	}
+	nowMillis := now.UnixMilli()
+	return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+		value, err := cursor.Value()
+		if err != nil {
+			return err
+		}
+		r, err := parseRecord(value)
+		if err != nil {
+			return err
+		}
+		if r.visibleAt > nowMillis {
+			return nil
+		}
+		return fn(cursor, r)
+	})
+}
+
+// getClaimed returns job's record, or ErrClaimExpired if it's no longer claimed by job
+func getClaimed(ctx context.Context, store *idb.ObjectStore, job *Job) (record, error) {
+	req, err := store.Get(jobKey(job))
+	if err != nil {
+		return record{}, err
+	}
+	value, err := req.Await(ctx)
+	if err != nil {
+		return record{}, err
+	}
+	if value.IsUndefined() {
+		return record{}, ErrClaimExpired
+	}
+	r, err := parseRecord(value)
+	if err != nil {
+		return record{}, err
+	}
+	if job.claim == "" || r.job.claim != job.claim {
+		return record{}, ErrClaimExpired
+	}
+	return r, nil
+}
+
+func moveToDead(cursor *idb.CursorWithValue, dead *idb.ObjectStore, r record) error {
+	if err := r.update(r.job.Attempts, r.visibleAt, ""); err != nil {
+		return err
+	}
+	if _, err := dead.Put(safejs.Unsafe(r.value)); err != nil {
+		return err
+	}
+	_, err := cursor.Delete()
+	return err
+}
+
+func jobKey(job *Job) js.Value {
+	key, err := safejs.ValueOf(job.ID)
+	if err != nil {
+		panic(err)
+	}
+	return safejs.Unsafe(key)
+}
+
+func newClaim() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
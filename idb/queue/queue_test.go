@@ -0,0 +1,185 @@
+//go:build js && wasm
+// +build js,wasm
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testQueue(tb testing.TB, options Options) (*Queue, *time.Time) {
+	tb.Helper()
+	db := idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		return CreateStores(db, "jobs")
+	})
+	q := Wrap(db, "jobs", options)
+	now := testNow
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		attempt int
+		expect  time.Duration
+	}{
+		{attempt: 0, expect: time.Second},
+		{attempt: 1, expect: time.Second},
+		{attempt: 2, expect: 2 * time.Second},
+		{attempt: 5, expect: 16 * time.Second},
+		{attempt: 100, expect: time.Hour},
+	} {
+		tc := tc // keep loop-local copy of test case for parallel runs
+		t.Run(fmt.Sprint(tc.attempt), func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expect, DefaultBackoff(tc.attempt))
+		})
+	}
+}
+
+func TestQueueOrder(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q, _ := testQueue(t, Options{})
+
+	for _, job := range []struct {
+		name     string
+		priority int
+	}{
+		{"low", -1},
+		{"first", 0},
+		{"high", 5},
+		{"second", 0},
+	} {
+		_, err := q.Enqueue(ctx, js.ValueOf(job.name), job.priority)
+		assert.NoError(t, err)
+	}
+	count, err := q.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	peeked, err := q.Peek(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "high", peeked.Payload.String())
+
+	var names []string
+	for {
+		job, err := q.Dequeue(ctx)
+		if err == ErrEmpty {
+			break
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Equal(t, testNow, job.EnqueuedAt.UTC())
+		names = append(names, job.Payload.String())
+		assert.NoError(t, q.Ack(ctx, job))
+	}
+	assert.Equal(t, []string{"high", "first", "second", "low"}, names)
+	count, err = q.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestQueueVisibilityTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q, now := testQueue(t, Options{VisibilityTimeout: time.Minute})
+
+	id, err := q.Enqueue(ctx, js.ValueOf("a"), 0)
+	assert.NoError(t, err)
+	job, err := q.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, id, job.ID)
+
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, ErrEmpty, err)
+	_, err = q.Peek(ctx)
+	assert.Equal(t, ErrEmpty, err)
+
+	*now = now.Add(time.Minute)
+	reclaimed, err := q.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, id, reclaimed.ID)
+	assert.Equal(t, 2, reclaimed.Attempts)
+
+	assert.Equal(t, ErrClaimExpired, q.Ack(ctx, job))
+	assert.Equal(t, ErrClaimExpired, q.Nack(ctx, job))
+	assert.NoError(t, q.Ack(ctx, reclaimed))
+	assert.Equal(t, ErrClaimExpired, q.Ack(ctx, reclaimed))
+}
+
+func TestQueueNack(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q, now := testQueue(t, Options{
+		MaxAttempts: 2,
+		Backoff:     func(attempt int) time.Duration { return time.Duration(attempt) * time.Second },
+	})
+
+	id, err := q.Enqueue(ctx, js.ValueOf("a"), 0)
+	assert.NoError(t, err)
+	job, err := q.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Nack(ctx, job))
+
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, ErrEmpty, err)
+	*now = now.Add(time.Second)
+	job, err = q.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Attempts)
+	assert.NoError(t, q.Nack(ctx, job))
+
+	count, err := q.Len(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	dead, err := q.DeadLetters(ctx)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(dead)) {
+		assert.Equal(t, id, dead[0].ID)
+		assert.Equal(t, 2, dead[0].Attempts)
+		assert.Equal(t, "a", dead[0].Payload.String())
+	}
+}
+
+func TestQueueDeadLetterExpiredClaim(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q, now := testQueue(t, Options{MaxAttempts: 1, VisibilityTimeout: time.Minute})
+
+	_, err := q.Enqueue(ctx, js.ValueOf("a"), 0)
+	assert.NoError(t, err)
+	_, err = q.Dequeue(ctx)
+	assert.NoError(t, err)
+
+	*now = now.Add(time.Minute)
+	_, err = q.Dequeue(ctx)
+	assert.Equal(t, ErrEmpty, err)
+	count, err := q.DeadLen(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestQueueLock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	q, _ := testQueue(t, Options{Lock: true})
+
+	_, err := q.Enqueue(ctx, js.ValueOf("a"), 0)
+	assert.NoError(t, err)
+	job, err := q.Dequeue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", job.Payload.String())
+	assert.NoError(t, q.Ack(ctx, job))
+}
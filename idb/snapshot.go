@@ -0,0 +1,103 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/hack-pad/safejs"
+)
+
+// keepAliveKey is the key counted by keep-alive requests. Counting a single key is cheap for every store.
+const keepAliveKey = 0
+
+var errSnapshotNoObjectStores = errors.New("Cannot snapshot a database without object stores")
+
+// Snapshot runs fn with a read-only transaction over every object store, which gives fn a consistent point-in-time view of the whole database.
+// The transaction stays active until fn returns, even while fn waits on other events, by continuously issuing small requests. The snapshot blocks read-write transactions on the same stores, so avoid long waits inside fn.
+//
+// If fn returns an error, the transaction is aborted and the error is returned. Otherwise, Snapshot waits for the transaction to complete.
+// If ctx is done before fn returns, the transaction is aborted and its requests fail.
+func (db *Database) Snapshot(ctx context.Context, fn func(txn *Transaction) error) error {
+	names, err := db.ObjectStoreNames()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errSnapshotNoObjectStores
+	}
+	txn, err := db.Transaction(TransactionReadOnly, names[0], names[1:]...)
+	if err != nil {
+		return err
+	}
+	stop, err := txn.keepAlive(names[0])
+	if err != nil {
+		_ = txn.Abort()
+		return err
+	}
+	if err := txn.runKeptAlive(ctx, stop, fn); err != nil {
+		_ = txn.Abort()
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return txn.Await(ctx)
+}
+
+// runKeptAlive runs fn, then stops the keep-alive requests even if fn panics. Stops them and aborts the transaction early if ctx is done.
+func (t *Transaction) runKeptAlive(ctx context.Context, stop func(), fn func(*Transaction) error) error {
+	fnDone := make(chan struct{})
+	defer close(fnDone)
+	defer stop()
+	go func() {
+		select {
+		case <-ctx.Done():
+			stop()
+			_ = t.Abort()
+		case <-fnDone:
+		}
+	}()
+	return fn(t)
+}
+
+// keepAlive prevents the transaction from auto-committing by issuing a new request against objectStoreName each time the previous one finishes, until stop is called. Calling stop more than once is safe.
+func (t *Transaction) keepAlive(objectStoreName string) (stop func(), err error) {
+	store, err := t.ObjectStore(objectStoreName)
+	if err != nil {
+		return nil, err
+	}
+	var stopped int32
+	var next safejs.Func
+	issue := func() error {
+		req, err := store.base.jsObjectStore.Call("count", keepAliveKey)
+		if err != nil {
+			return tryAsDOMException(err)
+		}
+		if _, err := req.Call(addEventListener, "success", next); err != nil {
+			return err
+		}
+		_, err = req.Call(addEventListener, "error", next)
+		return err
+	}
+	next, err = safejs.FuncOf(func(safejs.Value, []safejs.Value) interface{} {
+		if atomic.LoadInt32(&stopped) != 0 || issue() != nil {
+			// stopped or the transaction finished, so this is the last callback
+			next.Release()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := issue(); err != nil {
+		next.Release()
+		return nil, err
+	}
+	return func() {
+		atomic.StoreInt32(&stopped, 1)
+	}, nil
+}
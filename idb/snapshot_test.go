@@ -0,0 +1,132 @@
+//go:build js && wasm
+// +build js,wasm
+
+package idb
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestDatabaseSnapshot(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("store1", ObjectStoreOptions{})
+		assert.NoError(t, err)
+		_, err = db.CreateObjectStore("store2", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	putValue := func(value string) *Transaction {
+		txn, err := db.Transaction(TransactionReadWrite, "store1", "store2")
+		assert.NoError(t, err)
+		for _, name := range []string{"store1", "store2"} {
+			store, err := txn.ObjectStore(name)
+			assert.NoError(t, err)
+			_, err = store.PutKey(js.ValueOf("key"), js.ValueOf(value))
+			assert.NoError(t, err)
+		}
+		return txn
+	}
+	assert.NoError(t, putValue("before").Await(ctx))
+
+	var writeTxn *Transaction
+	err := db.Snapshot(ctx, func(txn *Transaction) error {
+		names, err := txn.ObjectStoreNames()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"store1", "store2"}, names)
+
+		writeTxn = putValue("after") // blocked until the snapshot completes
+		time.Sleep(50 * time.Millisecond)
+
+		for _, name := range names {
+			store, err := txn.ObjectStore(name)
+			assert.NoError(t, err)
+			req, err := store.Get(js.ValueOf("key"))
+			assert.NoError(t, err)
+			value, err := req.Await(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, js.ValueOf("before"), value)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, writeTxn.Await(ctx))
+}
+
+func TestDatabaseSnapshotError(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("store1", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	fnErr := errors.New("some error")
+	err := db.Snapshot(context.Background(), func(txn *Transaction) error {
+		return fnErr
+	})
+	assert.ErrorIs(t, err, fnErr)
+}
+
+func TestDatabaseSnapshotCanceled(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("store1", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := db.Snapshot(ctx, func(txn *Transaction) error {
+		cancel()
+		// the snapshot no longer blocks writers once ctx is canceled
+		writeCtx, writeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer writeCancel()
+		writeTxn, err := db.Transaction(TransactionReadWrite, "store1")
+		assert.NoError(t, err)
+		store, err := writeTxn.ObjectStore("store1")
+		assert.NoError(t, err)
+		_, err = store.PutKey(js.ValueOf("key"), js.ValueOf("value"))
+		assert.NoError(t, err)
+		assert.NoError(t, writeTxn.Await(writeCtx))
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDatabaseSnapshotPanic(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {
+		_, err := db.CreateObjectStore("store1", ObjectStoreOptions{})
+		assert.NoError(t, err)
+	})
+	assert.Panics(t, func() {
+		_ = db.Snapshot(context.Background(), func(txn *Transaction) error {
+			panic("some panic")
+		})
+	})
+
+	// the keep-alive requests stopped, so writers are no longer blocked
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	txn, err := db.Transaction(TransactionReadWrite, "store1")
+	assert.NoError(t, err)
+	store, err := txn.ObjectStore("store1")
+	assert.NoError(t, err)
+	_, err = store.PutKey(js.ValueOf("key"), js.ValueOf("value"))
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Await(ctx))
+}
+
+func TestDatabaseSnapshotNoObjectStores(t *testing.T) {
+	t.Parallel()
+	db := testDB(t, func(db *Database) {})
+	err := db.Snapshot(context.Background(), func(txn *Transaction) error {
+		t.Error("fn should not be called")
+		return nil
+	})
+	assert.ErrorIs(t, err, errSnapshotNoObjectStores)
+}
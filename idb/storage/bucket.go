@@ -0,0 +1,81 @@
+//go:build js && wasm
+// +build js,wasm
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/promise"
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsStorageBuckets safejs.Value
+
+	// ErrBucketsUnsupported is returned from OpenBucket when the Storage Buckets API is not supported, like outside Chromium-based browsers
+	ErrBucketsUnsupported = errors.New("Storage Buckets API is not supported")
+)
+
+func init() {
+	navigator, err := safejs.Global().Get("navigator")
+	if err != nil {
+		panic(err)
+	}
+	if !navigator.IsUndefined() {
+		jsStorageBuckets, err = navigator.Get("storageBuckets")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// BucketOptions contains all available options for opening a storage bucket
+type BucketOptions struct {
+	// Persisted requests the bucket is not evicted under storage pressure
+	Persisted bool
+	// Durability is the default durability of the bucket's transactions. Uses the browser's default if DurabilityDefault.
+	Durability idb.TransactionDurability
+	// Quota is the maximum bytes the bucket may use. Uses the browser's default if 0.
+	Quota uint64
+	// Expires is when the bucket may be deleted. Never expires if zero.
+	Expires time.Time
+}
+
+// OpenBucket opens or creates the named storage bucket, then returns its IndexedDB factory.
+// Databases in a bucket are isolated from other buckets, and are evicted or persisted along with their bucket.
+//
+// Returns ErrBucketsUnsupported if the browser does not support storage buckets.
+func OpenBucket(ctx context.Context, name string, options BucketOptions) (*idb.Factory, error) {
+	if jsStorageBuckets.IsUndefined() {
+		return nil, ErrBucketsUnsupported
+	}
+	optionsMap := map[string]interface{}{
+		"persisted": options.Persisted,
+	}
+	if options.Durability != idb.DurabilityDefault {
+		optionsMap["durability"] = options.Durability.String()
+	}
+	if options.Quota > 0 {
+		optionsMap["quota"] = float64(options.Quota)
+	}
+	if !options.Expires.IsZero() {
+		optionsMap["expires"] = float64(options.Expires.UnixMilli())
+	}
+	jsPromise, err := jsStorageBuckets.Call("open", name, optionsMap)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := promise.Await(ctx, jsPromise)
+	if err != nil {
+		return nil, err
+	}
+	jsFactory, err := bucket.Get("indexedDB")
+	if err != nil {
+		return nil, err
+	}
+	return idb.WrapFactory(safejs.Unsafe(jsFactory))
+}
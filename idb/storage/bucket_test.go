@@ -0,0 +1,42 @@
+//go:build js && wasm
+// +build js,wasm
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestOpenBucket(t *testing.T) {
+	t.Parallel()
+	factory, err := OpenBucket(context.Background(), "go-indexeddb-test-bucket", BucketOptions{
+		Durability: idb.DurabilityRelaxed,
+		Expires:    time.Now().Add(time.Hour),
+	})
+	if err == ErrBucketsUnsupported {
+		t.Skip(err)
+	}
+	assert.NoError(t, err)
+
+	const dbName = "bucket-db"
+	req, err := factory.Open(context.Background(), dbName, 0, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("mystore", idb.ObjectStoreOptions{})
+		return err
+	})
+	assert.NoError(t, err)
+	db, err := req.Await(context.Background())
+	assert.NoError(t, err)
+	names, err := db.ObjectStoreNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mystore"}, names)
+
+	assert.NoError(t, db.Close())
+	deleteReq, err := factory.DeleteDatabase(dbName)
+	assert.NoError(t, err)
+	assert.NoError(t, deleteReq.Await(context.Background()))
+}
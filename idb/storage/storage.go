@@ -0,0 +1,161 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package storage manages the storage quota and persistence of IndexedDB and other site data, using navigator.storage.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/promise"
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsStorage safejs.Value
+
+	errStorageUnsupported = errors.New("Storage API is not supported")
+	errQuotaExceeded      = idb.NewDOMException("QuotaExceededError")
+)
+
+func init() {
+	navigator, err := safejs.Global().Get("navigator")
+	if err != nil {
+		panic(err)
+	}
+	if !navigator.IsUndefined() {
+		jsStorage, err = navigator.Get("storage")
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func callStorage(ctx context.Context, method string) (safejs.Value, error) {
+	if jsStorage.IsUndefined() {
+		return safejs.Value{}, errStorageUnsupported
+	}
+	jsPromise, err := jsStorage.Call(method)
+	if err != nil {
+		return safejs.Value{}, err
+	}
+	return promise.Await(ctx, jsPromise)
+}
+
+// Estimate is an estimate of the storage used by this origin, in bytes
+type Estimate struct {
+	Usage uint64
+	Quota uint64
+}
+
+// Available returns the estimated bytes remaining before reaching the quota
+func (e Estimate) Available() uint64 {
+	if e.Usage >= e.Quota {
+		return 0
+	}
+	return e.Quota - e.Usage
+}
+
+// GetEstimate returns an estimate of how much storage this origin is using and how much is available
+func GetEstimate(ctx context.Context) (Estimate, error) {
+	jsEstimate, err := callStorage(ctx, "estimate")
+	if err != nil {
+		return Estimate{}, err
+	}
+	usage, err := getUint(jsEstimate, "usage")
+	if err != nil {
+		return Estimate{}, err
+	}
+	quota, err := getUint(jsEstimate, "quota")
+	if err != nil {
+		return Estimate{}, err
+	}
+	return Estimate{Usage: usage, Quota: quota}, nil
+}
+
+func getUint(value safejs.Value, property string) (uint64, error) {
+	propertyValue, err := value.Get(property)
+	if err != nil {
+		return 0, err
+	}
+	if propertyValue.IsUndefined() {
+		return 0, nil
+	}
+	number, err := propertyValue.Float()
+	return uint64(number), err
+}
+
+// Persist requests permission to make this origin's storage persistent, so it is not evicted under storage pressure. Returns true if storage is now persistent.
+func Persist(ctx context.Context) (bool, error) {
+	persisted, err := callStorage(ctx, "persist")
+	if err != nil {
+		return false, err
+	}
+	return persisted.Bool()
+}
+
+// Persisted returns true if this origin's storage is persistent
+func Persisted(ctx context.Context) (bool, error) {
+	persisted, err := callStorage(ctx, "persisted")
+	if err != nil {
+		return false, err
+	}
+	return persisted.Bool()
+}
+
+// QuotaError is a QuotaExceededError enriched with the storage estimate when it occurred.
+// Use errors.Is(err, idb.NewDOMException("QuotaExceededError")) to detect any quota error.
+type QuotaError struct {
+	Estimate
+	// Requested is the number of bytes requested in CheckAvailable. Zero for errors from WrapQuotaError.
+	Requested uint64
+	Err       error
+}
+
+func (e *QuotaError) Error() string {
+	if e.Requested > 0 {
+		return fmt.Sprintf("%v: requested %d bytes, but only %d of %d bytes are available", e.Err, e.Requested, e.Available(), e.Quota)
+	}
+	return fmt.Sprintf("%v: using %d of %d bytes", e.Err, e.Usage, e.Quota)
+}
+
+// Unwrap returns the underlying QuotaExceededError
+func (e *QuotaError) Unwrap() error {
+	return e.Err
+}
+
+// CheckAvailable is a pre-flight check before large writes. Returns a *QuotaError if the estimated available storage is less than the given number of bytes.
+// Estimates are imprecise, so a nil error does not guarantee the writes will succeed.
+func CheckAvailable(ctx context.Context, bytes uint64) error {
+	estimate, err := GetEstimate(ctx)
+	if err != nil {
+		return err
+	}
+	if estimate.Available() < bytes {
+		return &QuotaError{
+			Estimate:  estimate,
+			Requested: bytes,
+			Err:       errQuotaExceeded,
+		}
+	}
+	return nil
+}
+
+// WrapQuotaError enriches a QuotaExceededError, like from Transaction.Await, into a *QuotaError with the current storage estimate. Other errors are returned unchanged.
+func WrapQuotaError(ctx context.Context, err error) error {
+	var quotaErr *QuotaError
+	if !errors.Is(err, errQuotaExceeded) || errors.As(err, &quotaErr) {
+		return err
+	}
+	estimate, estimateErr := GetEstimate(ctx)
+	if estimateErr != nil {
+		return err
+	}
+	return &QuotaError{
+		Estimate: estimate,
+		Err:      err,
+	}
+}
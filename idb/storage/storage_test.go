@@ -0,0 +1,61 @@
+//go:build js && wasm
+// +build js,wasm
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestGetEstimate(t *testing.T) {
+	t.Parallel()
+	estimate, err := GetEstimate(context.Background())
+	assert.NoError(t, err)
+	assert.NotZero(t, estimate.Quota)
+	assert.Equal(t, estimate.Quota-estimate.Usage, estimate.Available())
+}
+
+func TestEstimateAvailable(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, uint64(2), Estimate{Usage: 1, Quota: 3}.Available())
+	assert.Equal(t, uint64(0), Estimate{Usage: 4, Quota: 3}.Available())
+}
+
+func TestPersisted(t *testing.T) {
+	t.Parallel()
+	_, err := Persisted(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestCheckAvailable(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, CheckAvailable(context.Background(), 1))
+
+	err := CheckAvailable(context.Background(), math.MaxUint64)
+	assert.ErrorIs(t, err, idb.NewDOMException("QuotaExceededError"))
+	var quotaErr *QuotaError
+	assert.Equal(t, true, errors.As(err, &quotaErr))
+	assert.Equal(t, uint64(math.MaxUint64), quotaErr.Requested)
+	assert.NotZero(t, quotaErr.Quota)
+}
+
+func TestWrapQuotaError(t *testing.T) {
+	t.Parallel()
+	otherErr := idb.NewDOMException("DataError")
+	assert.Equal(t, error(otherErr), WrapQuotaError(context.Background(), otherErr))
+	assert.NoError(t, WrapQuotaError(context.Background(), nil))
+
+	baseErr := idb.NewDOMException("QuotaExceededError")
+	err := WrapQuotaError(context.Background(), baseErr)
+	assert.ErrorIs(t, err, baseErr)
+	var quotaErr *QuotaError
+	assert.Equal(t, true, errors.As(err, &quotaErr))
+	assert.NotZero(t, quotaErr.Quota)
+	assert.Equal(t, err, WrapQuotaError(context.Background(), err))
+}
@@ -14,7 +14,9 @@ import (
 var (
 	supportsTransactionCommit = checkSupportsTransactionCommit()
 
-	errNotInTransaction = errors.New("Not part of a transaction")
+	errNotInTransaction  = errors.New("Not part of a transaction")
+	errNotVersionChange  = errors.New("Only allowed in a version change transaction, like inside an Upgrader")
+	errVersionChangeMode = errors.New("Version change transactions can't be created directly, use Database.UpgradeTransaction inside an Upgrader")
 )
 
 func checkSupportsTransactionCommit() bool {
@@ -47,12 +49,16 @@ const (
 	TransactionReadOnly TransactionMode = iota
 	// TransactionReadWrite allows reading and writing of data in existing data stores to be changed.
 	TransactionReadWrite
+	// TransactionVersionChange allows any operation to be performed, including ones that delete and create object stores and indexes. Only used for upgrades, see Database.UpgradeTransaction. Creating a transaction with this mode returns an error.
+	TransactionVersionChange
 )
 
 func parseMode(s string) TransactionMode {
 	switch s {
 	case "readwrite":
 		return TransactionReadWrite
+	case "versionchange":
+		return TransactionVersionChange
 	default:
 		return TransactionReadOnly
 	}
@@ -62,6 +68,8 @@ func (m TransactionMode) String() string {
 	switch m {
 	case TransactionReadWrite:
 		return "readwrite"
+	case TransactionVersionChange:
+		return "versionchange"
 	default:
 		return "readonly"
 	}
@@ -117,6 +125,7 @@ type Transaction struct {
 	db            *Database
 	jsTransaction safejs.Value
 	objectStores  map[string]*ObjectStore
+	changes       *changeSet // tracks changes for Database.Watch and Observe, nil if nothing is listening
 }
 
 func wrapTransaction(db *Database, jsTransaction safejs.Value) *Transaction {
@@ -193,6 +202,27 @@ func (t *Transaction) ObjectStore(name string) (*ObjectStore, error) {
 	return store, nil
 }
 
+// requireVersionChange returns an error unless t is a version change transaction
+func (t *Transaction) requireVersionChange() error {
+	if t == nil {
+		return errNotVersionChange
+	}
+	mode, err := t.Mode()
+	if err != nil {
+		return err
+	}
+	if mode != TransactionVersionChange {
+		return errNotVersionChange
+	}
+	return nil
+}
+
+// renameObjectStore caches a renamed ObjectStore under its new name
+func (t *Transaction) renameObjectStore(store *ObjectStore, oldName, newName string) {
+	delete(t.objectStores, oldName)
+	t.objectStores[newName] = store
+}
+
 // Commit for an active transaction, commits the transaction. Note that this doesn't normally have to be called — a transaction will automatically commit when all outstanding requests have been satisfied and no new requests have been made. Commit() can be used to start the commit process without waiting for events from outstanding requests to be dispatched.
 func (t *Transaction) Commit() error {
 	if !supportsTransactionCommit {
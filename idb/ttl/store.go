@@ -0,0 +1,259 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package ttl stores values in IndexedDB which expire after a time-to-live.
+//
+// Each record is stamped with its expiry time in milliseconds since the Unix epoch, stored in Options.Field. An index on that field lets Store.Sweep find and delete expired records efficiently. Expired records are hidden from reads until they're swept.
+package ttl
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	// DefaultField is the default field storing each record's expiry time
+	DefaultField = "$expires"
+	// DefaultIndexName is the default name of the index on each record's expiry time
+	DefaultIndexName = "$expires"
+
+	defaultSweepInterval = time.Minute
+	defaultBatchSize     = 100
+)
+
+var (
+	jsObject safejs.Value
+
+	errNotObject = errors.New("ttl: value must be an object")
+)
+
+func init() {
+	var err error
+	jsObject, err = safejs.Global().Get("Object")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Options contains all available options for a TTL Store
+type Options struct {
+	// TTL is how long records written by Add and Put live. Zero means records never expire.
+	TTL time.Duration
+	// Field is the name of the field storing each record's expiry time. Defaults to DefaultField.
+	Field string
+	// IndexName is the name of the index on Field. Defaults to DefaultIndexName.
+	IndexName string
+	// SweepInterval is the time between sweeps by Store.RunSweeper. Defaults to 1 minute.
+	SweepInterval time.Duration
+	// BatchSize is the maximum number of records deleted in each transaction by Store.Sweep. Defaults to 100.
+	BatchSize int
+}
+
+func (o Options) withDefaults() Options {
+	if o.Field == "" {
+		o.Field = DefaultField
+	}
+	if o.IndexName == "" {
+		o.IndexName = DefaultIndexName
+	}
+	if o.SweepInterval <= 0 {
+		o.SweepInterval = defaultSweepInterval
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultBatchSize
+	}
+	return o
+}
+
+// CreateIndex creates the expiry index on store. Call it in an Upgrader, on a new store or on an existing one from Database.UpgradeTransaction.
+func CreateIndex(store *idb.ObjectStore, options Options) (*idb.Index, error) {
+	options = options.withDefaults()
+	field, err := safejs.ValueOf(options.Field)
+	if err != nil {
+		return nil, err
+	}
+	return store.CreateIndex(options.IndexName, safejs.Unsafe(field), idb.IndexOptions{})
+}
+
+// Store expires values in an existing object store, which must have an index created by CreateIndex. Values must be objects.
+type Store struct {
+	db        *idb.Database
+	storeName string
+	options   Options
+	now       func() time.Time
+}
+
+// Wrap returns a Store which expires values in db's object store named storeName
+func Wrap(db *idb.Database, storeName string, options Options) *Store {
+	return &Store{
+		db:        db,
+		storeName: storeName,
+		options:   options.withDefaults(),
+		now:       time.Now,
+	}
+}
+
+// Get returns the value for key, or undefined if key does not exist or has expired
+func (s *Store) Get(ctx context.Context, key js.Value) (js.Value, error) {
+	var value js.Value
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := store.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = req.Await(ctx)
+		return err
+	})
+	if err != nil {
+		return js.Value{}, err
+	}
+	if s.expired(value, s.now()) {
+		return js.Undefined(), nil
+	}
+	return value, nil
+}
+
+// GetAll returns every unexpired value in keyRange, in key order. A nil keyRange returns all values.
+func (s *Store) GetAll(ctx context.Context, keyRange *idb.KeyRange) ([]js.Value, error) {
+	var values []js.Value
+	err := s.Iter(ctx, keyRange, func(cursor *idb.CursorWithValue) error {
+		value, err := cursor.Value()
+		values = append(values, value)
+		return err
+	})
+	return values, err
+}
+
+// Iter calls fn with a cursor on every unexpired record in keyRange, in key order. A nil keyRange iterates all records.
+// fn runs inside a single read-only transaction, so it must not wait on other events. Stops if fn returns an error, and returns that error.
+func (s *Store) Iter(ctx context.Context, keyRange *idb.KeyRange, fn func(*idb.CursorWithValue) error) error {
+	now := s.now()
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		var req *idb.CursorWithValueRequest
+		var err error
+		if keyRange == nil {
+			req, err = store.OpenCursor(idb.CursorNext)
+		} else {
+			req, err = store.OpenCursorRange(keyRange, idb.CursorNext)
+		}
+		if err != nil {
+			return err
+		}
+		return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			if s.expired(value, now) {
+				return nil
+			}
+			return fn(cursor)
+		})
+	})
+}
+
+// Add adds value with the given key, expiring after Options.TTL. key may be undefined for stores with a key generator or key path. Returns the new record's key.
+func (s *Store) Add(ctx context.Context, key, value js.Value) (js.Value, error) {
+	return s.AddTTL(ctx, key, value, s.options.TTL)
+}
+
+// AddTTL is the same as Add, but expires after ttl instead of Options.TTL
+func (s *Store) AddTTL(ctx context.Context, key, value js.Value, ttl time.Duration) (js.Value, error) {
+	return s.write(ctx, value, ttl, func(store *idb.ObjectStore, stamped js.Value) (*idb.Request, error) {
+		req, err := store.AddKey(key, stamped)
+		if err != nil {
+			return nil, err
+		}
+		return req.Request, nil
+	})
+}
+
+// Put puts value with the given key, expiring after Options.TTL. key may be undefined for stores with a key generator or key path. Returns the record's key.
+func (s *Store) Put(ctx context.Context, key, value js.Value) (js.Value, error) {
+	return s.PutTTL(ctx, key, value, s.options.TTL)
+}
+
+// PutTTL is the same as Put, but expires after ttl instead of Options.TTL
+func (s *Store) PutTTL(ctx context.Context, key, value js.Value, ttl time.Duration) (js.Value, error) {
+	return s.write(ctx, value, ttl, func(store *idb.ObjectStore, stamped js.Value) (*idb.Request, error) {
+		return store.PutKey(key, stamped)
+	})
+}
+
+func (s *Store) write(ctx context.Context, value js.Value, ttl time.Duration, writeFn func(*idb.ObjectStore, js.Value) (*idb.Request, error)) (js.Value, error) {
+	stamped, err := s.stamp(value, ttl)
+	if err != nil {
+		return js.Value{}, err
+	}
+	var resultKey js.Value
+	err = txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := writeFn(store, stamped)
+		if err != nil {
+			return err
+		}
+		resultKey, err = req.Await(ctx)
+		return err
+	})
+	return resultKey, err
+}
+
+// Delete deletes the record for key
+func (s *Store) Delete(ctx context.Context, key js.Value) error {
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		_, err := store.Delete(key)
+		return err
+	})
+}
+
+// ExpiresAt returns the expiry time of value, a record read from the store. Returns false if value never expires.
+func (s *Store) ExpiresAt(value js.Value) (time.Time, bool) {
+	millis, ok := s.expiresAtMillis(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(millis)), true
+}
+
+// stamp returns a shallow copy of value with its expiry field set, or removed if ttl is zero
+func (s *Store) stamp(value js.Value, ttl time.Duration) (js.Value, error) {
+	if safejs.Safe(value).Type() != safejs.TypeObject {
+		return js.Value{}, errNotObject
+	}
+	stamped, err := jsObject.Call("assign", map[string]interface{}{}, value)
+	if err != nil {
+		return js.Value{}, err
+	}
+	if ttl <= 0 {
+		if err := stamped.Delete(s.options.Field); err != nil {
+			return js.Value{}, err
+		}
+		return safejs.Unsafe(stamped), nil
+	}
+	expiresAt := s.now().Add(ttl).UnixMilli()
+	err = stamped.Set(s.options.Field, expiresAt)
+	return safejs.Unsafe(stamped), err
+}
+
+func (s *Store) expired(value js.Value, now time.Time) bool {
+	millis, ok := s.expiresAtMillis(value)
+	return ok && millis <= float64(now.UnixMilli())
+}
+
+func (s *Store) expiresAtMillis(value js.Value) (float64, bool) {
+	jsValue := safejs.Safe(value)
+	if jsValue.Type() != safejs.TypeObject {
+		return 0, false
+	}
+	field, err := jsValue.Get(s.options.Field)
+	if err != nil || field.Type() != safejs.TypeNumber {
+		return 0, false
+	}
+	millis, err := field.Float()
+	return millis, err == nil
+}
@@ -0,0 +1,133 @@
+//go:build js && wasm
+// +build js,wasm
+
+package ttl
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testStore(tb testing.TB, options Options) *Store {
+	tb.Helper()
+	db := idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		store, err := db.CreateObjectStore("cache", idb.ObjectStoreOptions{})
+		if err != nil {
+			return err
+		}
+		_, err = CreateIndex(store, options)
+		return err
+	})
+	store := Wrap(db, "cache", options)
+	store.now = func() time.Time { return testNow }
+	return store
+}
+
+func testValue(name string) js.Value {
+	return js.ValueOf(map[string]interface{}{"name": name})
+}
+
+func TestStorePutGet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{TTL: time.Minute})
+
+	value := testValue("a")
+	_, err := store.Put(ctx, js.ValueOf("a"), value)
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.Get(DefaultField).IsUndefined())
+
+	got, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a", got.Get("name").String())
+	expiresAt, ok := store.ExpiresAt(got)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, testNow.Add(time.Minute), expiresAt.UTC())
+
+	store.now = func() time.Time { return testNow.Add(time.Minute) }
+	got, err = store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, got.IsUndefined())
+
+	got, err = store.Get(ctx, js.ValueOf("missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, true, got.IsUndefined())
+}
+
+func TestStoreNoTTL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{Field: "expires", IndexName: "byExpiry"})
+
+	_, err := store.Put(ctx, js.ValueOf("a"), js.ValueOf(map[string]interface{}{"expires": 1}))
+	assert.NoError(t, err)
+	got, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	_, ok := store.ExpiresAt(got)
+	assert.Equal(t, false, ok)
+}
+
+func TestStoreAddTTL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{TTL: time.Minute})
+
+	_, err := store.AddTTL(ctx, js.ValueOf("a"), testValue("a"), time.Hour)
+	assert.NoError(t, err)
+	_, err = store.Add(ctx, js.ValueOf("a"), testValue("a"))
+	assert.Error(t, err)
+
+	got, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	expiresAt, _ := store.ExpiresAt(got)
+	assert.Equal(t, testNow.Add(time.Hour), expiresAt.UTC())
+
+	_, err = store.Put(ctx, js.ValueOf("b"), js.ValueOf("not an object"))
+	assert.ErrorIs(t, err, errNotObject)
+}
+
+func TestStoreGetAll(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{TTL: time.Minute})
+	for _, tc := range []struct {
+		key string
+		ttl time.Duration
+	}{
+		{"a", time.Second},
+		{"b", time.Hour},
+		{"c", 0},
+		{"d", time.Second},
+	} {
+		_, err := store.PutTTL(ctx, js.ValueOf(tc.key), testValue(tc.key), tc.ttl)
+		assert.NoError(t, err)
+	}
+	store.now = func() time.Time { return testNow.Add(time.Minute) }
+
+	values, err := store.GetAll(ctx, nil)
+	assert.NoError(t, err)
+	var names []string
+	for _, value := range values {
+		names = append(names, value.Get("name").String())
+	}
+	assert.Equal(t, []string{"b", "c"}, names)
+
+	keyRange, err := idb.NewKeyRangeLowerBound(js.ValueOf("c"), false)
+	assert.NoError(t, err)
+	values, err = store.GetAll(ctx, keyRange)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(values))
+
+	assert.NoError(t, store.Delete(ctx, js.ValueOf("b")))
+	values, err = store.GetAll(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(values))
+}
@@ -0,0 +1,80 @@
+//go:build js && wasm
+// +build js,wasm
+
+package ttl
+
+import (
+	"context"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+// Sweep deletes every expired record, in transactions of up to Options.BatchSize records. Returns the number of records deleted.
+func (s *Store) Sweep(ctx context.Context) (int, error) {
+	now := s.now()
+	total := 0
+	for {
+		deleted, err := s.sweepBatch(ctx, now)
+		total += deleted
+		if err != nil || deleted < s.options.BatchSize {
+			return total, err
+		}
+	}
+}
+
+// sweepBatch deletes up to Options.BatchSize records which expired at or before now
+func (s *Store) sweepBatch(ctx context.Context, now time.Time) (int, error) {
+	upper, err := safejs.ValueOf(now.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	keyRange, err := idb.NewKeyRangeUpperBound(safejs.Unsafe(upper), false)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	err = txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		index, err := store.Index(s.options.IndexName)
+		if err != nil {
+			return err
+		}
+		req, err := index.OpenCursorRange(keyRange, idb.CursorNext)
+		if err != nil {
+			return err
+		}
+		return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+			if _, err := cursor.Delete(); err != nil {
+				return err
+			}
+			deleted++
+			if deleted == s.options.BatchSize {
+				return idb.ErrCursorStopIter
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// RunSweeper calls Sweep every Options.SweepInterval, starting immediately, until ctx is canceled or a sweep fails. Run it in a new goroutine.
+// Returns the sweep's error, or ctx.Err() once canceled.
+func (s *Store) RunSweeper(ctx context.Context) error {
+	ticker := time.NewTicker(s.options.SweepInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Sweep(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
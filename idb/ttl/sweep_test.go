@@ -0,0 +1,71 @@
+//go:build js && wasm
+// +build js,wasm
+
+package ttl
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func countRecords(tb testing.TB, store *Store) uint {
+	tb.Helper()
+	txn, err := store.db.Transaction(idb.TransactionReadOnly, store.storeName)
+	assert.NoError(tb, err)
+	objectStore, err := txn.ObjectStore(store.storeName)
+	assert.NoError(tb, err)
+	req, err := objectStore.Count()
+	assert.NoError(tb, err)
+	count, err := req.Await(context.Background())
+	assert.NoError(tb, err)
+	return count
+}
+
+func TestStoreSweep(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{BatchSize: 2})
+	for i := 0; i < 5; i++ {
+		_, err := store.PutTTL(ctx, js.ValueOf(fmt.Sprint("expired", i)), testValue("expired"), time.Second)
+		assert.NoError(t, err)
+	}
+	_, err := store.PutTTL(ctx, js.ValueOf("fresh"), testValue("fresh"), time.Hour)
+	assert.NoError(t, err)
+	_, err = store.Put(ctx, js.ValueOf("forever"), testValue("forever"))
+	assert.NoError(t, err)
+
+	store.now = func() time.Time { return testNow.Add(time.Minute) }
+	deleted, err := store.Sweep(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, deleted)
+	assert.Equal(t, uint(2), countRecords(t, store))
+
+	deleted, err = store.Sweep(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+}
+
+func TestStoreRunSweeper(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	store := testStore(t, Options{SweepInterval: 10 * time.Millisecond})
+	_, err := store.PutTTL(ctx, js.ValueOf("a"), testValue("a"), time.Second)
+	assert.NoError(t, err)
+
+	store.now = func() time.Time { return testNow.Add(time.Minute) }
+	done := make(chan error, 1)
+	go func() {
+		done <- store.RunSweeper(ctx)
+	}()
+	assert.Eventually(t, func(context.Context) bool {
+		return countRecords(t, store) == 0
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,76 @@
+//go:build js && wasm
+// +build js,wasm
+
+package versioned
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+)
+
+// Cursor is a versioned record in Store.Iter. It's only valid for the duration of the callback.
+type Cursor struct {
+	cursor *idb.CursorWithValue
+	key    js.Value
+	record Record
+}
+
+// Key returns the record's key
+func (c *Cursor) Key() js.Value {
+	return c.key
+}
+
+// Record returns the record's value and revision
+func (c *Cursor) Record() Record {
+	return c.record
+}
+
+// Update replaces the record's value and increments its revision. Returns the new revision.
+// The record was read in the same transaction, so Update can't conflict with other writers.
+func (c *Cursor) Update(value js.Value) (uint64, error) {
+	record := Record{Rev: c.record.Rev + 1, Value: value}
+	envelope, err := newEnvelope(record)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.cursor.Update(envelope); err != nil {
+		return 0, err
+	}
+	c.record = record
+	return record.Rev, nil
+}
+
+// Delete deletes the record
+func (c *Cursor) Delete() error {
+	_, err := c.cursor.Delete()
+	return err
+}
+
+// Iter calls fn with each record in key order, in a single read-write transaction. Return idb.ErrCursorStopIter to stop early.
+// fn runs inside the transaction, so it must not wait on other asynchronous work.
+func (s *Store) Iter(ctx context.Context, fn func(*Cursor) error) error {
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		req, err := store.OpenCursor(idb.CursorNext)
+		if err != nil {
+			return err
+		}
+		return req.Iter(ctx, func(cursor *idb.CursorWithValue) error {
+			key, err := cursor.Key()
+			if err != nil {
+				return err
+			}
+			value, err := cursor.Value()
+			if err != nil {
+				return err
+			}
+			record, err := parseRecord(value)
+			if err != nil {
+				return err
+			}
+			return fn(&Cursor{cursor: cursor, key: key, record: record})
+		})
+	})
+}
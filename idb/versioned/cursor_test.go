@@ -0,0 +1,51 @@
+//go:build js && wasm
+// +build js,wasm
+
+package versioned
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+)
+
+func TestStoreIter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{})
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := store.Put(ctx, js.ValueOf(key), js.ValueOf(key))
+		assert.NoError(t, err)
+	}
+
+	var keys []string
+	err := store.Iter(ctx, func(cursor *Cursor) error {
+		key := cursor.Key().String()
+		keys = append(keys, key)
+		assert.Equal(t, uint64(1), cursor.Record().Rev)
+		switch key {
+		case "a":
+			rev, err := cursor.Update(js.ValueOf("updated"))
+			assert.Equal(t, uint64(2), rev)
+			assert.Equal(t, uint64(2), cursor.Record().Rev)
+			return err
+		case "b":
+			return cursor.Delete()
+		default:
+			return nil
+		}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+
+	record, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), record.Rev)
+	assert.Equal(t, "updated", record.Value.String())
+	record, err = store.Get(ctx, js.ValueOf("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), record.Rev)
+}
@@ -0,0 +1,202 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Package versioned stores records with revision numbers in IndexedDB, for optimistic concurrency between tabs.
+//
+// Each record is stored in an envelope with its revision, which starts at 1 and increases with every write. UpdateIf only writes a record if its revision hasn't changed since it was read, and otherwise returns a *ConflictError or resolves the conflict with Options.Merge.
+// Every read, compare, and write runs in a single read-write transaction, so concurrent writers can't interleave.
+package versioned
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/txnutil"
+	"github.com/hack-pad/safejs"
+)
+
+const (
+	revField   = "rev"
+	valueField = "value"
+)
+
+var (
+	// ErrConflict is wrapped by every *ConflictError. Use errors.Is(err, ErrConflict) to detect any conflict.
+	ErrConflict = errors.New("versioned: revision conflict")
+
+	errNotVersioned = errors.New("versioned: record is not a versioned envelope")
+)
+
+// ConflictError is returned when a record's revision doesn't match the expected revision
+type ConflictError struct {
+	Key js.Value
+	// Expected is the revision passed to UpdateIf or DeleteIf
+	Expected uint64
+	// Actual is the record's current revision, or 0 if it doesn't exist
+	Actual uint64
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v: expected revision %d, but found %d", ErrConflict, e.Expected, e.Actual)
+}
+
+// Unwrap returns ErrConflict
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// Record is a value and its revision
+type Record struct {
+	// Rev is the record's revision, or 0 if it doesn't exist
+	Rev uint64
+	// Value is the record's value, or undefined if it doesn't exist
+	Value js.Value
+}
+
+// MergeFunc resolves a conflict in UpdateIf between the current record and the proposed value. Returns the value to write instead.
+// MergeFunc runs inside the write's transaction, so it must not wait on other asynchronous work. Return an error, like ErrConflict, to fail the update.
+type MergeFunc func(key js.Value, current Record, proposed js.Value) (js.Value, error)
+
+// Options contains all available options for a versioned Store
+type Options struct {
+	// Merge resolves conflicts in UpdateIf. If nil, conflicts return a *ConflictError.
+	Merge MergeFunc
+}
+
+// Store is a versioned record store in an existing object store with out-of-line keys
+type Store struct {
+	db        *idb.Database
+	storeName string
+	options   Options
+}
+
+// Wrap returns a Store for the object store named storeName
+func Wrap(db *idb.Database, storeName string, options Options) *Store {
+	return &Store{
+		db:        db,
+		storeName: storeName,
+		options:   options,
+	}
+}
+
+// Get returns the record at key. Missing records have revision 0.
+func (s *Store) Get(ctx context.Context, key js.Value) (Record, error) {
+	var record Record
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadOnly, s.storeName, func(store *idb.ObjectStore) error {
+		var err error
+		record, err = get(ctx, store, key)
+		return err
+	})
+	return record, err
+}
+
+// Put writes value at key regardless of its current revision. Returns the new revision.
+func (s *Store) Put(ctx context.Context, key, value js.Value) (uint64, error) {
+	var rev uint64
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		current, err := get(ctx, store, key)
+		if err != nil {
+			return err
+		}
+		rev = current.Rev + 1
+		return put(store, key, Record{Rev: rev, Value: value})
+	})
+	return rev, err
+}
+
+// UpdateIf writes value at key if the record's revision is expectedRev. Pass 0 to only create a new record. Returns the new revision.
+// On a mismatch, calls Options.Merge and writes its value instead, or returns a *ConflictError if Merge is nil.
+func (s *Store) UpdateIf(ctx context.Context, key js.Value, expectedRev uint64, value js.Value) (uint64, error) {
+	var rev uint64
+	err := txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		current, err := get(ctx, store, key)
+		if err != nil {
+			return err
+		}
+		if current.Rev != expectedRev {
+			if s.options.Merge == nil {
+				return &ConflictError{Key: key, Expected: expectedRev, Actual: current.Rev}
+			}
+			value, err = s.options.Merge(key, current, value)
+			if err != nil {
+				return err
+			}
+		}
+		rev = current.Rev + 1
+		return put(store, key, Record{Rev: rev, Value: value})
+	})
+	return rev, err
+}
+
+// DeleteIf deletes the record at key if its revision is expectedRev. Returns a *ConflictError on a mismatch.
+func (s *Store) DeleteIf(ctx context.Context, key js.Value, expectedRev uint64) error {
+	return txnutil.RunStore(ctx, s.db, idb.TransactionReadWrite, s.storeName, func(store *idb.ObjectStore) error {
+		current, err := get(ctx, store, key)
+		if err != nil {
+			return err
+		}
+		if current.Rev != expectedRev {
+			return &ConflictError{Key: key, Expected: expectedRev, Actual: current.Rev}
+		}
+		_, err = store.Delete(key)
+		return err
+	})
+}
+
+func get(ctx context.Context, store *idb.ObjectStore, key js.Value) (Record, error) {
+	req, err := store.Get(key)
+	if err != nil {
+		return Record{}, err
+	}
+	value, err := req.Await(ctx)
+	if err != nil {
+		return Record{}, err
+	}
+	if value.IsUndefined() {
+		return Record{Value: js.Undefined()}, nil
+	}
+	return parseRecord(value)
+}
+
+func put(store *idb.ObjectStore, key js.Value, record Record) error {
+	envelope, err := newEnvelope(record)
+	if err != nil {
+		return err
+	}
+	_, err = store.PutKey(key, envelope)
+	return err
+}
+
+func newEnvelope(record Record) (js.Value, error) {
+	envelope, err := safejs.ValueOf(map[string]interface{}{
+		revField:   record.Rev,
+		valueField: record.Value,
+	})
+	return safejs.Unsafe(envelope), err
+}
+
+func parseRecord(envelope js.Value) (Record, error) {
+	jsEnvelope := safejs.Safe(envelope)
+	if jsEnvelope.Type() != safejs.TypeObject {
+		return Record{}, errNotVersioned
+	}
+	rev, err := jsEnvelope.Get(revField)
+	if err != nil {
+		return Record{}, err
+	}
+	if rev.Type() != safejs.TypeNumber {
+		return Record{}, errNotVersioned
+	}
+	revFloat, err := rev.Float()
+	if err != nil {
+		return Record{}, err
+	}
+	value, err := jsEnvelope.Get(valueField)
+	if err != nil {
+		return Record{}, err
+	}
+	return Record{Rev: uint64(revFloat), Value: safejs.Unsafe(value)}, nil
+}
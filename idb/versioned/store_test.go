@@ -0,0 +1,127 @@
+//go:build js && wasm
+// +build js,wasm
+
+package versioned
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"testing"
+
+	"github.com/hack-pad/go-indexeddb/idb"
+	"github.com/hack-pad/go-indexeddb/idb/internal/assert"
+	"github.com/hack-pad/go-indexeddb/idb/internal/idbtest"
+)
+
+func testStore(tb testing.TB, options Options) *Store {
+	tb.Helper()
+	db := idbtest.DB(tb, func(db *idb.Database, oldVersion, newVersion uint) error {
+		_, err := db.CreateObjectStore("docs", idb.ObjectStoreOptions{})
+		return err
+	})
+	return Wrap(db, "docs", options)
+}
+
+func TestStorePutGet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{})
+
+	record, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), record.Rev)
+	assert.Equal(t, true, record.Value.IsUndefined())
+
+	rev, err := store.Put(ctx, js.ValueOf("a"), js.ValueOf("first"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), rev)
+	rev, err = store.Put(ctx, js.ValueOf("a"), js.ValueOf("second"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), rev)
+
+	record, err = store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), record.Rev)
+	assert.Equal(t, "second", record.Value.String())
+}
+
+func TestStoreUpdateIf(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{})
+
+	rev, err := store.UpdateIf(ctx, js.ValueOf("a"), 0, js.ValueOf("created"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), rev)
+
+	_, err = store.UpdateIf(ctx, js.ValueOf("a"), 0, js.ValueOf("created again"))
+	assert.Equal(t, true, errors.Is(err, ErrConflict))
+	var conflict *ConflictError
+	if assert.Equal(t, true, errors.As(err, &conflict)) {
+		assert.Equal(t, uint64(0), conflict.Expected)
+		assert.Equal(t, uint64(1), conflict.Actual)
+	}
+
+	rev, err = store.UpdateIf(ctx, js.ValueOf("a"), 1, js.ValueOf("updated"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), rev)
+
+	_, err = store.UpdateIf(ctx, js.ValueOf("a"), 1, js.ValueOf("stale"))
+	assert.Equal(t, true, errors.Is(err, ErrConflict))
+	record, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), record.Rev)
+	assert.Equal(t, "updated", record.Value.String())
+}
+
+func TestStoreUpdateIfMerge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{
+		Merge: func(key js.Value, current Record, proposed js.Value) (js.Value, error) {
+			if proposed.String() == "reject" {
+				return js.Undefined(), ErrConflict
+			}
+			return js.ValueOf(current.Value.String() + "+" + proposed.String()), nil
+		},
+	})
+
+	_, err := store.Put(ctx, js.ValueOf("a"), js.ValueOf("theirs"))
+	assert.NoError(t, err)
+	rev, err := store.UpdateIf(ctx, js.ValueOf("a"), 0, js.ValueOf("ours"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), rev)
+	record, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, "theirs+ours", record.Value.String())
+
+	_, err = store.UpdateIf(ctx, js.ValueOf("a"), 1, js.ValueOf("reject"))
+	assert.Equal(t, ErrConflict, err)
+	record, err = store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), record.Rev)
+}
+
+func TestStoreDeleteIf(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := testStore(t, Options{})
+
+	_, err := store.Put(ctx, js.ValueOf("a"), js.ValueOf("value"))
+	assert.NoError(t, err)
+	err = store.DeleteIf(ctx, js.ValueOf("a"), 2)
+	assert.Equal(t, true, errors.Is(err, ErrConflict))
+	assert.NoError(t, store.DeleteIf(ctx, js.ValueOf("a"), 1))
+
+	record, err := store.Get(ctx, js.ValueOf("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), record.Rev)
+}
+
+func TestConflictError(t *testing.T) {
+	t.Parallel()
+	err := &ConflictError{Key: js.ValueOf("a"), Expected: 1, Actual: 2}
+	assert.Equal(t, "versioned: revision conflict: expected revision 1, but found 2", err.Error())
+	assert.Equal(t, true, errors.Is(err, ErrConflict))
+}
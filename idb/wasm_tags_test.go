@@ -14,9 +14,16 @@ import (
 
 func TestAllWasmTags(t *testing.T) {
 	walkErr := filepath.Walk(".", func(path string, info fs.FileInfo, err error) error {
-		if err != nil || info.IsDir() {
+		if err != nil {
 			return err
 		}
+		if info.IsDir() {
+			if path == "idbkey" {
+				// idbkey is portable and intentionally builds on every platform
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if path == "wasm_tags_test.go" {
 			// ignore this file, since it must run with file system support enabled
 			return nil